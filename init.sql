@@ -1,7 +1,69 @@
 CREATE TABLE IF NOT EXISTS deductions (
-    personal float NOT NULL DEFAULT 0,
-    maximum_k_receipt float NOT NULL DEFAULT 0
+    personal numeric(14,2) NOT NULL DEFAULT 0,
+    maximum_k_receipt numeric(14,2) NOT NULL DEFAULT 0,
+    spouse numeric(14,2) NOT NULL DEFAULT 60000,
+    child numeric(14,2) NOT NULL DEFAULT 30000,
+    child_bonus numeric(14,2) NOT NULL DEFAULT 60000,
+    parent numeric(14,2) NOT NULL DEFAULT 30000,
+    disability numeric(14,2) NOT NULL DEFAULT 60000
 );
 
-INSERT INTO deductions VALUES
-(60000,50000);
\ No newline at end of file
+-- upgrade a deductions table created before the amounts became exact and
+-- the household deductions were added
+ALTER TABLE deductions
+    ALTER COLUMN personal TYPE numeric(14,2),
+    ALTER COLUMN maximum_k_receipt TYPE numeric(14,2),
+    ADD COLUMN IF NOT EXISTS spouse numeric(14,2) NOT NULL DEFAULT 60000,
+    ADD COLUMN IF NOT EXISTS child numeric(14,2) NOT NULL DEFAULT 30000,
+    ADD COLUMN IF NOT EXISTS child_bonus numeric(14,2) NOT NULL DEFAULT 60000,
+    ADD COLUMN IF NOT EXISTS parent numeric(14,2) NOT NULL DEFAULT 30000,
+    ADD COLUMN IF NOT EXISTS disability numeric(14,2) NOT NULL DEFAULT 60000;
+
+INSERT INTO deductions(personal,maximum_k_receipt)
+SELECT 60000,50000 WHERE NOT EXISTS (SELECT 1 FROM deductions);
+
+CREATE TABLE IF NOT EXISTS deduction_versions (
+    version bigserial PRIMARY KEY,
+    personal numeric(14,2) NOT NULL,
+    maximum_k_receipt numeric(14,2) NOT NULL,
+    spouse numeric(14,2) NOT NULL,
+    child numeric(14,2) NOT NULL,
+    child_bonus numeric(14,2) NOT NULL,
+    parent numeric(14,2) NOT NULL,
+    disability numeric(14,2) NOT NULL,
+    created_at timestamptz NOT NULL DEFAULT now()
+);
+
+INSERT INTO deduction_versions(personal,maximum_k_receipt,spouse,child,child_bonus,parent,disability)
+SELECT personal,maximum_k_receipt,spouse,child,child_bonus,parent,disability FROM deductions
+WHERE NOT EXISTS (SELECT 1 FROM deduction_versions);
+
+CREATE TABLE IF NOT EXISTS tax_brackets (
+    tax_year int NOT NULL,
+    lower_bound numeric(14,2) NOT NULL,
+    upper_bound numeric(14,2),
+    tax_rate int NOT NULL,
+    PRIMARY KEY (tax_year, lower_bound)
+);
+
+-- the tax brackets of every year at each version of deduction_versions
+CREATE TABLE IF NOT EXISTS tax_bracket_versions (
+    version bigint NOT NULL REFERENCES deduction_versions(version),
+    tax_year int NOT NULL,
+    lower_bound numeric(14,2) NOT NULL,
+    upper_bound numeric(14,2),
+    tax_rate int NOT NULL,
+    PRIMARY KEY (version, tax_year, lower_bound)
+);
+
+INSERT INTO tax_bracket_versions(version,tax_year,lower_bound,upper_bound,tax_rate)
+SELECT (SELECT max(version) FROM deduction_versions),tax_year,lower_bound,upper_bound,tax_rate FROM tax_brackets
+WHERE NOT EXISTS (SELECT 1 FROM tax_bracket_versions);
+
+CREATE TABLE IF NOT EXISTS calculations (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    input jsonb NOT NULL,
+    setting jsonb NOT NULL,
+    output jsonb NOT NULL,
+    created_at timestamptz NOT NULL DEFAULT now()
+);
@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"github.com/labstack/echo/v4"
+	"github.com/thosaphol/assessment-tax/pkg/bracket"
 	"github.com/thosaphol/assessment-tax/pkg/deduction"
 	"github.com/thosaphol/assessment-tax/pkg/middleware/auth"
 	"github.com/thosaphol/assessment-tax/pkg/repo/postgres"
@@ -44,16 +45,26 @@ func main() {
 	}
 
 	hd := deduction.New(p)
+	hb := bracket.New(p)
 	h := tax.New(p)
 
 	e := echo.New()
 	e.POST("/tax/calculations", h.Calculation)
+	e.GET("/tax/calculations/:id", h.SavedCalculation)
+	e.POST("/tax/calculations/household", h.CalculationHousehold)
+	e.POST("/tax/calculations/gross-up", h.CalculationGrossUp)
+	e.POST("/tax/calculations/payroll", h.CalculationPayroll)
+	e.POST("/tax/scenarios", h.CalculationScenarios)
+	e.POST("/tax/optimize", h.CalculationOptimize)
 	e.POST("tax/calculations/upload-csv", h.CalculationCSV)
 
 	g := e.Group("/admin")
 	g.Use(auth.NewBasicAuth(user, pass))
 	g.POST("/deductions/personal", hd.SetDeductionPersonal)
 	g.POST("/deductions/k-receipt", hd.SetDeductionKReceipt)
+	g.POST("/deductions/household", hd.SetDeductionHousehold)
+	g.GET("/tax-brackets/:year", hb.TaxBrackets)
+	g.PUT("/tax-brackets/:year", hb.SetTaxBrackets)
 
 	//
 	// graceful shutdown
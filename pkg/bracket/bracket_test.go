@@ -0,0 +1,218 @@
+package bracket
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/thosaphol/assessment-tax/pkg/engine"
+	"github.com/thosaphol/assessment-tax/pkg/money"
+	"github.com/thosaphol/assessment-tax/pkg/repo"
+	req "github.com/thosaphol/assessment-tax/pkg/request"
+	resp "github.com/thosaphol/assessment-tax/pkg/response"
+)
+
+type StubStore struct {
+	taxConsts map[int][]engine.TaxConst
+	err       error
+}
+
+func (stubStore StubStore) SetPersonalDeduction(amount money.Money) error {
+	return stubStore.err
+}
+func (stubStore StubStore) SetKReceiptDeduction(amount money.Money) error {
+	return stubStore.err
+}
+
+func (stubStore StubStore) SetHouseholdDeduction(d engine.HouseholdDeduction) error {
+	return stubStore.err
+}
+
+func (stubStore StubStore) SetTaxBrackets(year int, tConsts []engine.TaxConst) error {
+	return stubStore.err
+}
+
+func (stubStore StubStore) TaxBrackets(year int) ([]engine.TaxConst, error) {
+	return stubStore.taxConsts[year], stubStore.err
+}
+
+func (stubStore StubStore) TaxBracketSnapshot(version int64, year int) ([]engine.TaxConst, error) {
+	return nil, stubStore.err
+}
+
+func (stubStore StubStore) DeductionSnapshot(version int64) (repo.DeductionSnapshot, error) {
+	return repo.DeductionSnapshot{}, stubStore.err
+}
+
+func (stubStore StubStore) SaveCalculations(cs []repo.Calculation) ([]string, error) {
+	return nil, stubStore.err
+}
+
+func (stubStore StubStore) Calculation(id string) (repo.Calculation, error) {
+	return repo.Calculation{}, stubStore.err
+}
+
+func moneyPtr(f float64) *money.Money {
+	m := money.Baht(f)
+	return &m
+}
+
+func TestSetTaxBrackets(t *testing.T) {
+	tt := []struct {
+		name     string
+		year     string
+		body     req.TaxBrackets
+		err      error
+		wantCode int
+		wantBody any
+	}{
+		{
+			name:     "given year is not a number should return code 400 and message",
+			year:     "abc",
+			wantCode: http.StatusBadRequest,
+			wantBody: resp.Err{Message: "TaxYear must be a positive number."},
+		},
+		{
+			name: "given overlap tax brackets should return code 400 and message",
+			year: "2569",
+			body: req.TaxBrackets{TaxBrackets: []req.TaxBracket{
+				{Lower: money.Baht(0), Upper: moneyPtr(150000), TaxRate: 0},
+				{Lower: money.Baht(100000), TaxRate: 10},
+			}},
+			wantCode: http.StatusBadRequest,
+			wantBody: resp.Err{Message: "Tax bracket 2 overlaps the previous bracket."},
+		},
+		{
+			name: "given store error should return code 500 and message",
+			year: "2569",
+			body: req.TaxBrackets{TaxBrackets: []req.TaxBracket{
+				{Lower: money.Baht(0), Upper: moneyPtr(150000), TaxRate: 0},
+				{Lower: money.Baht(150000), TaxRate: 10},
+			}},
+			err:      errors.New("database error"),
+			wantCode: http.StatusInternalServerError,
+			wantBody: resp.Err{Message: "Found Internal Server Error"},
+		},
+		{
+			name: "given valid tax brackets should return code 200 and tax brackets",
+			year: "2569",
+			body: req.TaxBrackets{TaxBrackets: []req.TaxBracket{
+				{Lower: money.Baht(0), Upper: moneyPtr(150000), TaxRate: 0},
+				{Lower: money.Baht(150000), TaxRate: 10},
+			}},
+			wantCode: http.StatusOK,
+			wantBody: resp.TaxBrackets{TaxYear: 2569, TaxBrackets: []resp.TaxBracket{
+				{Level: "0-150,000", Lower: money.Baht(0), Upper: moneyPtr(150000), TaxRate: 0},
+				{Level: "150,001 ขึ้นไป", Lower: money.Baht(150000), TaxRate: 10},
+			}},
+		},
+	}
+
+	for _, tCase := range tt {
+		t.Run(tCase.name, func(t *testing.T) {
+			bytesObj, _ := json.Marshal(tCase.body)
+
+			req := httptest.NewRequest(http.MethodPut, "/", strings.NewReader(string(bytesObj)))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+
+			e := echo.New()
+			c := e.NewContext(req, rec)
+			c.SetPath("/admin/tax-brackets/:year")
+			c.SetParamNames("year")
+			c.SetParamValues(tCase.year)
+
+			h := New(StubStore{err: tCase.err})
+
+			h.SetTaxBrackets(c)
+			if rec.Code != tCase.wantCode {
+				t.Errorf("expected code %v but got code %v", tCase.wantCode, rec.Code)
+			}
+
+			got := reflect.New(reflect.TypeOf(tCase.wantBody))
+			if err := json.Unmarshal(rec.Body.Bytes(), got.Interface()); err != nil {
+				t.Errorf("unable to unmarshal json: %v", err)
+			}
+			if !reflect.DeepEqual(got.Elem().Interface(), tCase.wantBody) {
+				t.Errorf("expected %v but got %v", tCase.wantBody, got.Elem().Interface())
+			}
+		})
+	}
+}
+
+func TestTaxBrackets(t *testing.T) {
+	stored := map[int][]engine.TaxConst{
+		2569: {
+			engine.NewTaxConst(money.Baht(0), money.Baht(100000), 0),
+			engine.NewTaxConst(money.Baht(100000), money.Unlimited, 10),
+		},
+	}
+
+	tt := []struct {
+		name      string
+		year      string
+		wantCode  int
+		wantYear  int
+		wantLevel []string
+	}{
+		{
+			name:      "given stored year should return code 200 and stored tax brackets",
+			year:      "2569",
+			wantCode:  http.StatusOK,
+			wantYear:  2569,
+			wantLevel: []string{"0-100,000", "100,001 ขึ้นไป"},
+		},
+		{
+			name:      "given built-in year should return code 200 and built-in tax brackets",
+			year:      "2567",
+			wantCode:  http.StatusOK,
+			wantYear:  2567,
+			wantLevel: []string{"0-150,000", "150,001-500,000", "500,001-1,000,000", "1,000,001-2,000,000", "2,000,001 ขึ้นไป"},
+		},
+		{
+			name:     "given unknown year should return code 404",
+			year:     "2500",
+			wantCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tCase := range tt {
+		t.Run(tCase.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			rec := httptest.NewRecorder()
+
+			e := echo.New()
+			c := e.NewContext(req, rec)
+			c.SetPath("/admin/tax-brackets/:year")
+			c.SetParamNames("year")
+			c.SetParamValues(tCase.year)
+
+			h := New(StubStore{taxConsts: stored})
+
+			h.TaxBrackets(c)
+			if rec.Code != tCase.wantCode {
+				t.Errorf("expected code %v but got code %v", tCase.wantCode, rec.Code)
+			}
+			if tCase.wantCode != http.StatusOK {
+				return
+			}
+
+			var got resp.TaxBrackets
+			if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+				t.Errorf("unable to unmarshal json: %v", err)
+			}
+			var gotLevel []string
+			for _, b := range got.TaxBrackets {
+				gotLevel = append(gotLevel, b.Level)
+			}
+			if got.TaxYear != tCase.wantYear || !reflect.DeepEqual(gotLevel, tCase.wantLevel) {
+				t.Errorf("expected %v %v but got %v %v", tCase.wantYear, tCase.wantLevel, got.TaxYear, gotLevel)
+			}
+		})
+	}
+}
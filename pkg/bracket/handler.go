@@ -0,0 +1,89 @@
+package bracket
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/thosaphol/assessment-tax/pkg/engine"
+	"github.com/thosaphol/assessment-tax/pkg/repo"
+	"github.com/thosaphol/assessment-tax/pkg/request"
+	"github.com/thosaphol/assessment-tax/pkg/response"
+)
+
+type Handler struct {
+	store repo.Storer
+}
+
+func New(db repo.Storer) *Handler {
+	return &Handler{store: db}
+}
+
+func (h *Handler) TaxBrackets(c echo.Context) error {
+	year, err := taxYearParam(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.Err{Message: err.Error()})
+	}
+
+	tConsts, err := h.store.TaxBrackets(year)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, response.Err{Message: "Found Internal Server Error"})
+	}
+	if len(tConsts) == 0 {
+		tConsts, err = engine.GetTaxConsts(year)
+		if err != nil {
+			return c.JSON(http.StatusNotFound, response.Err{Message: err.Error()})
+		}
+	}
+
+	return c.JSON(http.StatusOK, toResponse(year, tConsts))
+}
+
+func (h *Handler) SetTaxBrackets(c echo.Context) error {
+	year, err := taxYearParam(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.Err{Message: err.Error()})
+	}
+
+	var tb request.TaxBrackets
+	err = c.Bind(&tb)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.Err{Message: err.Error()})
+	}
+
+	tConsts := tb.TaxConsts()
+	err = engine.ValidateTaxConsts(tConsts)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.Err{Message: err.Error()})
+	}
+
+	err = h.store.SetTaxBrackets(year, tConsts)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, response.Err{Message: "Found Internal Server Error"})
+	}
+
+	return c.JSON(http.StatusOK, toResponse(year, tConsts))
+}
+
+func taxYearParam(c echo.Context) (int, error) {
+	year, err := strconv.Atoi(c.Param("year"))
+	if err != nil || year <= 0 {
+		return 0, errors.New("TaxYear must be a positive number.")
+	}
+	return year, nil
+}
+
+func toResponse(year int, tConsts []engine.TaxConst) response.TaxBrackets {
+	var brackets []response.TaxBracket
+	for _, tConst := range tConsts {
+		b := response.TaxBracket{Level: tConst.Level, Lower: tConst.Lower, TaxRate: tConst.TaxRate}
+		if !tConst.Upper.IsUnlimited() {
+			upper := tConst.Upper
+			b.Upper = &upper
+		}
+		brackets = append(brackets, b)
+	}
+	return response.TaxBrackets{TaxYear: year, TaxBrackets: brackets}
+}
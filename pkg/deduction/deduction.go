@@ -1,6 +1,12 @@
 package deduction
 
+import (
+	"github.com/thosaphol/assessment-tax/pkg/engine"
+	"github.com/thosaphol/assessment-tax/pkg/money"
+)
+
 type Deduction struct {
-	Personal    float64
-	MaxKReceipt float64
+	Personal    money.Money
+	MaxKReceipt money.Money
+	Household   engine.HouseholdDeduction
 }
@@ -10,6 +10,9 @@ import (
 	"testing"
 
 	"github.com/labstack/echo/v4"
+	"github.com/thosaphol/assessment-tax/pkg/engine"
+	"github.com/thosaphol/assessment-tax/pkg/money"
+	"github.com/thosaphol/assessment-tax/pkg/repo"
 	"github.com/thosaphol/assessment-tax/pkg/request"
 	req "github.com/thosaphol/assessment-tax/pkg/request"
 	"github.com/thosaphol/assessment-tax/pkg/response"
@@ -22,19 +25,39 @@ type StubStore struct {
 }
 
 // Wallets implements Storer.
-func (stubStore StubStore) SetPersonalDeduction(amount float64) error {
+func (stubStore StubStore) SetPersonalDeduction(amount money.Money) error {
 	return stubStore.err
 }
-func (stubStore StubStore) PersonalDeduction() (float64, error) {
-	return stubStore.deduction.Personal, stubStore.err
+func (stubStore StubStore) SetKReceiptDeduction(amount money.Money) error {
+	return stubStore.err
 }
 
-func (stubStore StubStore) SetKReceiptDeduction(amount float64) error {
+func (stubStore StubStore) SetHouseholdDeduction(d engine.HouseholdDeduction) error {
 	return stubStore.err
 }
 
-func (stubStore StubStore) KReceiptDeduction() (float64, error) {
-	return stubStore.deduction.MaxKReceipt, stubStore.err
+func (stubStore StubStore) SetTaxBrackets(year int, tConsts []engine.TaxConst) error {
+	return stubStore.err
+}
+
+func (stubStore StubStore) TaxBrackets(year int) ([]engine.TaxConst, error) {
+	return nil, stubStore.err
+}
+
+func (stubStore StubStore) TaxBracketSnapshot(version int64, year int) ([]engine.TaxConst, error) {
+	return nil, stubStore.err
+}
+
+func (stubStore StubStore) DeductionSnapshot(version int64) (repo.DeductionSnapshot, error) {
+	return repo.DeductionSnapshot{}, stubStore.err
+}
+
+func (stubStore StubStore) SaveCalculations(cs []repo.Calculation) ([]string, error) {
+	return nil, stubStore.err
+}
+
+func (stubStore StubStore) Calculation(id string) (repo.Calculation, error) {
+	return repo.Calculation{}, stubStore.err
 }
 
 func TestPersonalDeductionValidation(t *testing.T) {
@@ -52,19 +75,19 @@ func TestPersonalDeductionValidation(t *testing.T) {
 		},
 		{
 			name:     "given amount deduction less than 0 should return code 400 and message",
-			d:        req.PersonalDeduction{Amount: -1},
+			d:        req.PersonalDeduction{Amount: money.Baht(-1)},
 			wantCode: http.StatusBadRequest,
 			wantBody: resp.Err{Message: "Amount: Invalid amount is required 10,000.0 to 100,000.0"},
 		},
 		{
 			name:     "given amount deduction greater than 100,000.0 should return code 400 and message",
-			d:        req.PersonalDeduction{Amount: 100001.0},
+			d:        req.PersonalDeduction{Amount: money.Baht(100001.0)},
 			wantCode: http.StatusBadRequest,
 			wantBody: resp.Err{Message: "Amount: Invalid amount is required 10,000.0 to 100,000.0"},
 		},
 		{
 			name:     "given amount deduction in range 10,000.0 to 100,000.0 should return code 200 and response",
-			d:        req.PersonalDeduction{Amount: 10200.0},
+			d:        req.PersonalDeduction{Amount: money.Baht(10200.0)},
 			wantCode: http.StatusOK,
 		},
 	}
@@ -124,24 +147,24 @@ func TestKReceiptDeductionValidation(t *testing.T) {
 		},
 		{
 			name:     "given amount deduction less than 0 should return code 400 and message",
-			d:        req.KReceiptDeduction{Amount: -1},
+			d:        req.KReceiptDeduction{Amount: money.Baht(-1)},
 			wantCode: http.StatusBadRequest,
 			wantBody: resp.Err{Message: "Amount: Invalid amount is required 0.0 to 100,000.0"},
 		},
 		{
 			name:     "given amount deduction greater than 100,000.0 should return code 400 and message",
-			d:        req.PersonalDeduction{Amount: 100001.0},
+			d:        req.PersonalDeduction{Amount: money.Baht(100001.0)},
 			wantCode: http.StatusBadRequest,
 			wantBody: resp.Err{Message: "Amount: Invalid amount is required 0.0 to 100,000.0"},
 		},
 		{
 			name:     "given amount deduction is 0.0 should return code 200",
-			d:        req.PersonalDeduction{Amount: 0.0},
+			d:        req.PersonalDeduction{Amount: money.Baht(0.0)},
 			wantCode: http.StatusOK,
 		},
 		{
 			name:     "given amount deduction is 100,000.0 should return code 200",
-			d:        req.PersonalDeduction{Amount: 100000.0},
+			d:        req.PersonalDeduction{Amount: money.Baht(100000.0)},
 			wantCode: http.StatusOK,
 		},
 	}
@@ -193,7 +216,7 @@ func TestDeductionError(t *testing.T) {
 	}
 
 	t.Run("given correct deduction should return code 500 and message", func(t *testing.T) {
-		body := req.PersonalDeduction{Amount: 10200.0}
+		body := req.PersonalDeduction{Amount: money.Baht(10200.0)}
 
 		bytesObj, _ := json.Marshal(body)
 		req := httptest.NewRequest(http.MethodPost, "/admin/deductions/personal", strings.NewReader(string(bytesObj)))
@@ -226,7 +249,7 @@ func TestDeductionError(t *testing.T) {
 	})
 
 	t.Run("given correct k-receipt deduction should return code 500 and message", func(t *testing.T) {
-		body := req.PersonalDeduction{Amount: 10200.0}
+		body := req.PersonalDeduction{Amount: money.Baht(10200.0)}
 
 		bytesObj, _ := json.Marshal(body)
 		req := httptest.NewRequest(http.MethodPost, "/admin/deductions/k-receipt", strings.NewReader(string(bytesObj)))
@@ -268,15 +291,15 @@ func TestPersonalDeduction(t *testing.T) {
 	}{
 		{
 			name:     "personal deduction is 70000 when amount is 70000",
-			d:        request.PersonalDeduction{Amount: 70000},
+			d:        request.PersonalDeduction{Amount: money.Baht(70000)},
 			wantCode: http.StatusOK,
-			wantBody: response.PersonalDeduction{PersonalDeduction: 70000},
+			wantBody: response.PersonalDeduction{PersonalDeduction: money.Baht(70000)},
 		},
 		{
 			name:     "personal deduction is 50000 when amount is 50000",
-			d:        request.PersonalDeduction{Amount: 50000},
+			d:        request.PersonalDeduction{Amount: money.Baht(50000)},
 			wantCode: http.StatusOK,
-			wantBody: response.PersonalDeduction{PersonalDeduction: 50000},
+			wantBody: response.PersonalDeduction{PersonalDeduction: money.Baht(50000)},
 		},
 	}
 
@@ -332,15 +355,15 @@ func TestKReceiptDeduction(t *testing.T) {
 	}{
 		{
 			name:     "k-receipt deduction is 70000 when amount is 70000",
-			d:        request.KReceiptDeduction{Amount: 70000},
+			d:        request.KReceiptDeduction{Amount: money.Baht(70000)},
 			wantCode: http.StatusOK,
-			wantBody: response.KReceiptDeduction{KReceipt: 70000},
+			wantBody: response.KReceiptDeduction{KReceipt: money.Baht(70000)},
 		},
 		{
 			name:     "k-receipt deduction is 50000 when amount is 50000",
-			d:        request.KReceiptDeduction{Amount: 50000},
+			d:        request.KReceiptDeduction{Amount: money.Baht(50000)},
 			wantCode: http.StatusOK,
-			wantBody: response.KReceiptDeduction{KReceipt: 50000},
+			wantBody: response.KReceiptDeduction{KReceipt: money.Baht(50000)},
 		},
 	}
 
@@ -386,3 +409,96 @@ func TestKReceiptDeduction(t *testing.T) {
 	}
 
 }
+
+func TestHouseholdDeduction(t *testing.T) {
+	household := request.HouseholdDeduction{
+		Spouse:     money.Baht(60000),
+		Child:      money.Baht(30000),
+		ChildBonus: money.Baht(60000),
+		Parent:     money.Baht(30000),
+		Disability: money.Baht(60000),
+	}
+	overChild := household
+	overChild.Child = money.Baht(100001)
+
+	tt := []struct {
+		name     string
+		d        any
+		wantCode int
+		wantBody any
+	}{
+		{
+			name:     "given incorrect structure should return code 400 and message",
+			d:        req.PersonalDeduction{Amount: money.Baht(60000)},
+			wantCode: http.StatusBadRequest,
+			wantBody: resp.Err{Message: "Json structure invalid"},
+		},
+		{
+			name:     "given child greater than 100,000.0 should return code 400 and message",
+			d:        overChild,
+			wantCode: http.StatusBadRequest,
+			wantBody: resp.Err{Message: "Child: Invalid child is required 0.0 to 100,000.0"},
+		},
+		{
+			name:     "given household deduction should return code 200 and response",
+			d:        household,
+			wantCode: http.StatusOK,
+			wantBody: resp.HouseholdDeduction{
+				Spouse:     money.Baht(60000),
+				Child:      money.Baht(30000),
+				ChildBonus: money.Baht(60000),
+				Parent:     money.Baht(30000),
+				Disability: money.Baht(60000),
+			},
+		},
+	}
+
+	stubstore := StubStore{
+		deduction: Deduction{},
+		err:       nil,
+	}
+
+	for _, tCase := range tt {
+		t.Run(tCase.name, func(t *testing.T) {
+			bytesObj, _ := json.Marshal(tCase.d)
+
+			req := httptest.NewRequest(http.MethodPost, "/admin/deductions/household", strings.NewReader(string(bytesObj)))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+
+			e := echo.New()
+			c := e.NewContext(req, rec)
+			c.SetPath("/admin/deductions/household")
+
+			h := New(stubstore)
+
+			var wantCode = tCase.wantCode
+			var wantBody = tCase.wantBody
+
+			h.SetDeductionHousehold(c)
+			var gotCode = rec.Code
+			gotJson := rec.Body.Bytes()
+			var gotBody any
+			if wantCode == http.StatusOK {
+				var body resp.HouseholdDeduction
+				if err := json.Unmarshal(gotJson, &body); err != nil {
+					t.Errorf("unable to unmarshal json: %v", err)
+				}
+				gotBody = body
+			} else {
+				var body resp.Err
+				if err := json.Unmarshal(gotJson, &body); err != nil {
+					t.Errorf("unable to unmarshal json: %v", err)
+				}
+				gotBody = body
+			}
+
+			if gotCode != wantCode {
+				t.Errorf("expected code %v but got code %v", wantCode, gotCode)
+			}
+			if !reflect.DeepEqual(gotBody, wantBody) {
+				t.Errorf("expected %v but got %v", wantBody, gotBody)
+			}
+		})
+	}
+}
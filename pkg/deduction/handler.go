@@ -69,3 +69,25 @@ func (h *Handler) SetDeductionKReceipt(c echo.Context) error {
 	var resp = response.KReceiptDeduction{KReceipt: k.Amount}
 	return c.JSON(http.StatusOK, resp)
 }
+
+func (h *Handler) SetDeductionHousehold(c echo.Context) error {
+	var hd request.HouseholdDeduction
+	var reqH map[string]interface{}
+	err := c.Bind(&reqH)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.Err{Message: err.Error()})
+	}
+
+	err = hd.BindFromMap(reqH)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.Err{Message: err.Error()})
+	}
+
+	err = h.store.SetHouseholdDeduction(hd.Deduction())
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, response.Err{Message: "Found Internal Server Error"})
+	}
+
+	var resp = response.HouseholdDeduction(hd)
+	return c.JSON(http.StatusOK, resp)
+}
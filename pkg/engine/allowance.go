@@ -0,0 +1,211 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/thosaphol/assessment-tax/pkg/money"
+)
+
+type Allowance struct {
+	AllowanceType string
+	Amount        money.Money
+}
+
+// AllowanceRule declares how much of an allowance type can be deducted.
+// A zero Cap or IncomeRate means no such limit. SettingCap reads a cap
+// configured by the admin. Group shares a combined cap with the other types
+// of the same group on top of the own limits. Multiplier counts the claimed
+// amount more than once, e.g. 2 for a double donation. HalfYear lets the
+// type be claimed in the half-year return, with half of the caps.
+type AllowanceRule struct {
+	Type       string
+	Cap        money.Money
+	IncomeRate int
+	SettingCap func(Setting) money.Money
+	Group      string
+	Multiplier int
+	HalfYear   bool
+}
+
+// AllowanceGroup is the combined cap of the types of a group. NetIncomeRate
+// limits the group to a percent of the income left after expenses, the
+// personal deduction and every allowance outside such groups.
+type AllowanceGroup struct {
+	Name          string
+	Cap           money.Money
+	NetIncomeRate int
+}
+
+// AllowanceResult reports the claimed and the allowed amount of a type. Cap
+// is the limit computed from the net income, if the type has one. Unused is
+// the part of Allowed left over once the net income reached 0.
+type AllowanceResult struct {
+	AllowanceType string
+	Claimed       money.Money
+	Allowed       money.Money
+	Cap           *money.Money
+	Unused        money.Money
+}
+
+const (
+	GroupRetirement = "retirement"
+	GroupInsurance  = "insurance"
+	GroupDonation   = "donation"
+)
+
+var allowanceRules = map[string]AllowanceRule{}
+
+var allowanceGroups = map[string]AllowanceGroup{}
+
+func init() {
+	for _, rule := range []AllowanceRule{
+		{Type: "donation", Group: GroupDonation, HalfYear: true},
+		{Type: "donation-double", Group: GroupDonation, Multiplier: 2, HalfYear: true},
+		{Type: "k-receipt", SettingCap: func(s Setting) money.Money { return s.MaxKReceipt }},
+		{Type: "life-insurance", Group: GroupInsurance, Cap: money.Baht(100000), HalfYear: true},
+		{Type: "health-insurance", Group: GroupInsurance, Cap: money.Baht(25000), HalfYear: true},
+		{Type: "parents-health-insurance", Cap: money.Baht(15000), HalfYear: true},
+		{Type: "pension-insurance", Group: GroupRetirement, Cap: money.Baht(200000), IncomeRate: 15},
+		{Type: "provident-fund", Group: GroupRetirement, Cap: money.Baht(500000), IncomeRate: 15},
+		{Type: "gpf", Group: GroupRetirement, Cap: money.Baht(500000), IncomeRate: 30},
+		{Type: "rmf", Group: GroupRetirement, Cap: money.Baht(500000), IncomeRate: 30},
+		{Type: "ssf", Group: GroupRetirement, Cap: money.Baht(200000), IncomeRate: 30},
+		{Type: "thai-esg", Cap: money.Baht(300000), IncomeRate: 30},
+		{Type: "nsf", Group: GroupRetirement, Cap: money.Baht(30000)},
+		{Type: "home-loan-interest", Cap: money.Baht(100000), HalfYear: true},
+		{Type: "social-security", Cap: money.Baht(9000), HalfYear: true},
+	} {
+		RegisterAllowance(rule)
+	}
+	for _, group := range []AllowanceGroup{
+		{Name: GroupRetirement, Cap: money.Baht(500000)},
+		{Name: GroupInsurance, Cap: money.Baht(100000)},
+		{Name: GroupDonation, NetIncomeRate: 10},
+	} {
+		RegisterAllowanceGroup(group)
+	}
+}
+
+// RegisterAllowance adds or replaces the rule of an allowance type.
+func RegisterAllowance(rule AllowanceRule) {
+	allowanceRules[rule.Type] = rule
+}
+
+// RegisterAllowanceGroup adds or replaces the combined cap of a group.
+func RegisterAllowanceGroup(group AllowanceGroup) {
+	allowanceGroups[group.Name] = group
+}
+
+func validateAllowance(alws []Allowance) error {
+	for _, alw := range alws {
+		if alw.Amount.IsNegative() {
+			return errors.New("Amount allowance must greater than 0.")
+		}
+		if _, ok := allowanceRules[alw.AllowanceType]; !ok {
+			return fmt.Errorf("AllowanceType '%s' is not supported.", alw.AllowanceType)
+		}
+	}
+	return nil
+}
+
+// calculateAllowance sums the allowed amount of every type, limited by the
+// rule of the type against the gross income and then by the combined cap of
+// its group in the order the types are claimed. Groups capped by the net
+// income, e.g. donations, are computed last from what the others leave.
+func calculateAllowance(alws []Allowance, gross, net money.Money, s Setting) (money.Money, []AllowanceResult) {
+	var results []AllowanceResult
+	index := map[string]int{}
+	counted := map[string]money.Money{}
+	for _, alw := range alws {
+		rule := allowanceRules[alw.AllowanceType]
+		amount := alw.Amount
+		if rule.Multiplier > 1 {
+			amount = amount.MulRatio(int64(rule.Multiplier), 1)
+		}
+		i, ok := index[rule.Type]
+		if !ok {
+			i = len(results)
+			index[rule.Type] = i
+			results = append(results, AllowanceResult{AllowanceType: rule.Type})
+		}
+		results[i].Claimed = results[i].Claimed.Add(alw.Amount)
+		counted[rule.Type] = counted[rule.Type].Add(amount)
+	}
+
+	remaining := map[string]money.Money{}
+	for name, group := range allowanceGroups {
+		if group.NetIncomeRate == 0 {
+			remaining[name] = s.cap(group.Cap)
+		}
+	}
+
+	total := money.Zero
+	for i, r := range results {
+		rule := allowanceRules[r.AllowanceType]
+		if allowanceGroups[rule.Group].NetIncomeRate > 0 || !s.claimable(rule) {
+			continue
+		}
+		allowed := allowedAmount(rule, counted[r.AllowanceType], gross, s)
+		if left, ok := remaining[rule.Group]; ok {
+			allowed = money.Min(allowed, left)
+			remaining[rule.Group] = left.Sub(allowed)
+		}
+		results[i].Allowed = allowed
+		total = total.Add(allowed)
+	}
+
+	left := money.Max(net.Sub(s.Personal).Sub(total), money.Zero)
+	caps := map[string]money.Money{}
+	for name, group := range allowanceGroups {
+		if group.NetIncomeRate == 0 {
+			continue
+		}
+		caps[name] = left.Percent(group.NetIncomeRate)
+		if !group.Cap.IsZero() {
+			caps[name] = money.Min(caps[name], s.cap(group.Cap))
+		}
+		remaining[name] = caps[name]
+	}
+
+	for i, r := range results {
+		rule := allowanceRules[r.AllowanceType]
+		groupCap, ok := caps[rule.Group]
+		if !ok || !s.claimable(rule) {
+			continue
+		}
+		allowed := money.Min(allowedAmount(rule, counted[r.AllowanceType], gross, s), remaining[rule.Group])
+		remaining[rule.Group] = remaining[rule.Group].Sub(allowed)
+		results[i].Allowed = allowed
+		results[i].Cap = &groupCap
+		total = total.Add(allowed)
+	}
+	return total, results
+}
+
+func allowedAmount(rule AllowanceRule, claimed, income money.Money, s Setting) money.Money {
+	allowed := claimed
+	if !rule.Cap.IsZero() {
+		allowed = money.Min(allowed, s.cap(rule.Cap))
+	}
+	if rule.IncomeRate > 0 {
+		allowed = money.Min(allowed, income.Percent(rule.IncomeRate))
+	}
+	if rule.SettingCap != nil {
+		allowed = money.Min(allowed, rule.SettingCap(s))
+	}
+	return allowed
+}
+
+// claimable tells whether the rule can be claimed in the period of s.
+func (s Setting) claimable(rule AllowanceRule) bool {
+	return !s.halfYear || rule.HalfYear
+}
+
+// cap halves a cap in the half-year period.
+func (s Setting) cap(amount money.Money) money.Money {
+	if s.halfYear {
+		return amount.MulRatio(1, 2)
+	}
+	return amount
+}
@@ -0,0 +1,127 @@
+package engine
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/thosaphol/assessment-tax/pkg/money"
+)
+
+func TestCalculateAllowance(t *testing.T) {
+	tt := []struct {
+		name   string
+		alws   []Allowance
+		income money.Money
+		want   money.Money
+	}{
+		{
+			name:   "donation is capped at 10% of income after personal deduction",
+			alws:   []Allowance{{AllowanceType: "donation", Amount: money.Baht(80000)}, {AllowanceType: "donation", Amount: money.Baht(40000)}},
+			income: money.Baht(1000000),
+			want:   money.Baht(94000),
+		},
+		{
+			name: "donation-double counts twice and shares the donation cap after other allowances",
+			alws: []Allowance{
+				{AllowanceType: "donation-double", Amount: money.Baht(15000)},
+				{AllowanceType: "donation", Amount: money.Baht(10000)},
+				{AllowanceType: "rmf", Amount: money.Baht(100000)},
+			},
+			income: money.Baht(500000),
+			want:   money.Baht(134000),
+		},
+		{
+			name:   "k-receipt is capped by setting",
+			alws:   []Allowance{{AllowanceType: "k-receipt", Amount: money.Baht(65000)}},
+			income: money.Baht(1000000),
+			want:   money.Baht(50000),
+		},
+		{
+			name:   "rmf is capped at 30% of income",
+			alws:   []Allowance{{AllowanceType: "rmf", Amount: money.Baht(200000)}},
+			income: money.Baht(500000),
+			want:   money.Baht(150000),
+		},
+		{
+			name:   "ssf is capped at 200,000",
+			alws:   []Allowance{{AllowanceType: "ssf", Amount: money.Baht(300000)}},
+			income: money.Baht(2000000),
+			want:   money.Baht(200000),
+		},
+		{
+			name: "independent types are summed",
+			alws: []Allowance{
+				{AllowanceType: "life-insurance", Amount: money.Baht(20000)},
+				{AllowanceType: "social-security", Amount: money.Baht(10000)},
+				{AllowanceType: "home-loan-interest", Amount: money.Baht(120000)},
+			},
+			income: money.Baht(1000000),
+			want:   money.Baht(129000),
+		},
+		{
+			name: "retirement group is capped at 500,000",
+			alws: []Allowance{
+				{AllowanceType: "rmf", Amount: money.Baht(400000)},
+				{AllowanceType: "ssf", Amount: money.Baht(200000)},
+				{AllowanceType: "provident-fund", Amount: money.Baht(100000)},
+			},
+			income: money.Baht(3000000),
+			want:   money.Baht(500000),
+		},
+		{
+			name: "insurance group is capped at 100,000",
+			alws: []Allowance{
+				{AllowanceType: "life-insurance", Amount: money.Baht(90000)},
+				{AllowanceType: "health-insurance", Amount: money.Baht(25000)},
+			},
+			income: money.Baht(1000000),
+			want:   money.Baht(100000),
+		},
+	}
+
+	for _, tCase := range tt {
+		t.Run(tCase.name, func(t *testing.T) {
+			got, _ := calculateAllowance(tCase.alws, tCase.income, tCase.income, setting)
+			if got != tCase.want {
+				t.Errorf("expected %v but got %v", tCase.want, got)
+			}
+		})
+	}
+}
+
+func TestCalculateAllowanceResults(t *testing.T) {
+	alws := []Allowance{
+		{AllowanceType: "rmf", Amount: money.Baht(200000)},
+		{AllowanceType: "donation", Amount: money.Baht(20000)},
+		{AllowanceType: "ssf", Amount: money.Baht(250000)},
+		{AllowanceType: "rmf", Amount: money.Baht(200000)},
+		{AllowanceType: "gpf", Amount: money.Baht(50000)},
+	}
+	want := []AllowanceResult{
+		{AllowanceType: "rmf", Claimed: money.Baht(400000), Allowed: money.Baht(400000)},
+		{AllowanceType: "donation", Claimed: money.Baht(20000), Allowed: money.Baht(20000), Cap: moneyPtr(144000)},
+		{AllowanceType: "ssf", Claimed: money.Baht(250000), Allowed: money.Baht(100000)},
+		{AllowanceType: "gpf", Claimed: money.Baht(50000), Allowed: money.Baht(0)},
+	}
+
+	total, got := calculateAllowance(alws, money.Baht(2000000), money.Baht(2000000), setting)
+	if total != money.Baht(520000) {
+		t.Errorf("expected total %v but got %v", money.Baht(520000), total)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v but got %v", want, got)
+	}
+}
+
+func TestRegisterAllowance(t *testing.T) {
+	RegisterAllowance(AllowanceRule{Type: "test-fund", Cap: money.Baht(1000)})
+	defer delete(allowanceRules, "test-fund")
+
+	alws := []Allowance{{AllowanceType: "test-fund", Amount: money.Baht(5000)}}
+	if err := validateAllowance(alws); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, _ := calculateAllowance(alws, money.Baht(100000), money.Baht(100000), setting); got != money.Baht(1000) {
+		t.Errorf("expected %v but got %v", money.Baht(1000), got)
+	}
+}
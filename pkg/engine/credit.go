@@ -0,0 +1,81 @@
+package engine
+
+import (
+	"errors"
+
+	"github.com/thosaphol/assessment-tax/pkg/money"
+)
+
+// maxCorporateRate is the highest corporate income tax rate a dividend can
+// be credited at.
+const maxCorporateRate = 30
+
+// Dividend is a Thai dividend, 40(4)(b), paid from profit taxed at
+// CorporateRate, 0 when the profit was exempt. Wht is the 10% withheld.
+type Dividend struct {
+	Amount        money.Money
+	CorporateRate int
+	Wht           money.Money
+}
+
+func validateCredits(in Input) error {
+	for _, d := range in.Dividends {
+		if d.Amount.IsNegative() {
+			return errors.New("Amount dividend must have a starting value of 0.")
+		}
+		if d.CorporateRate < 0 || d.CorporateRate > maxCorporateRate {
+			return errors.New("CorporateRate must be in the range 0 to 30.")
+		}
+		if d.Wht.IsNegative() || d.Wht.GreaterThan(d.Amount) {
+			return errors.New("Wht of dividend must be in the range 0 to Amount.")
+		}
+	}
+	for _, inc := range in.Incomes {
+		if inc.ForeignTax.IsNegative() || inc.ForeignTax.GreaterThan(inc.Amount) {
+			return errors.New("ForeignTax must be in the range 0 to Amount income.")
+		}
+	}
+	return nil
+}
+
+// dividendCredit grosses the dividend up to the profit before the corporate
+// tax and returns the tax credited, dividend * rate / (100 - rate).
+func dividendCredit(d Dividend) money.Money {
+	return d.Amount.MulRatio(int64(d.CorporateRate), int64(100-d.CorporateRate))
+}
+
+// withDividends includes the dividends with their credit as 40(4) income
+// when the taxpayer does not take the withholding as final tax, and returns
+// the withholding and the dividend credit to credit.
+func withDividends(in Input) (Input, money.Money, money.Money) {
+	if !in.IncludeDividends {
+		return in, money.Zero, money.Zero
+	}
+	wht, credit := money.Zero, money.Zero
+	incomes := append([]Income(nil), in.Incomes...)
+	for _, d := range in.Dividends {
+		c := dividendCredit(d)
+		incomes = append(incomes, Income{Category: "40(4)", Amount: d.Amount.Add(c)})
+		wht = wht.Add(d.Wht)
+		credit = credit.Add(c)
+	}
+	in.Incomes = incomes
+	return in, wht, credit
+}
+
+// foreignTaxCredit credits the tax paid abroad on each income up to the
+// Thai tax attributable to it, tax * amount / gross.
+func foreignTaxCredit(incomes []Income, tax, gross money.Money) money.Money {
+	credit := money.Zero
+	if !gross.GreaterThan(money.Zero) {
+		return credit
+	}
+	for _, inc := range incomes {
+		if inc.ForeignTax.IsZero() {
+			continue
+		}
+		attributable := tax.MulRatio(inc.Amount.Satang(), gross.Satang())
+		credit = credit.Add(money.Min(inc.ForeignTax, attributable))
+	}
+	return credit
+}
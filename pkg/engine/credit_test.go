@@ -0,0 +1,101 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/thosaphol/assessment-tax/pkg/money"
+)
+
+func TestCalculateCredits(t *testing.T) {
+	dividend := Dividend{Amount: money.Baht(100000), CorporateRate: 20, Wht: money.Baht(10000)}
+	tt := []struct {
+		name               string
+		in                 Input
+		wantTax            money.Money
+		wantDividendWht    money.Money
+		wantDividendCredit money.Money
+		wantForeignCredit  money.Money
+	}{
+		{
+			name:    "dividend taken as final tax should not be taxed",
+			in:      Input{TotalIncome: money.Baht(500000), Dividends: []Dividend{dividend}},
+			wantTax: money.Baht(29000),
+		},
+		{
+			name:               "dividend included should be grossed up and credited at 20/80",
+			in:                 Input{TotalIncome: money.Baht(500000), Dividends: []Dividend{dividend}, IncludeDividends: true},
+			wantTax:            money.Baht(9750),
+			wantDividendWht:    money.Baht(10000),
+			wantDividendCredit: money.Baht(25000),
+		},
+		{
+			name:            "dividend from exempt profit should have no credit",
+			in:              Input{TotalIncome: money.Baht(500000), Dividends: []Dividend{{Amount: money.Baht(100000), Wht: money.Baht(10000)}}, IncludeDividends: true},
+			wantTax:         money.Baht(31000),
+			wantDividendWht: money.Baht(10000),
+		},
+		{
+			name: "foreign tax should be credited up to the Thai tax attributable to the income",
+			in: Input{Incomes: []Income{
+				{Category: "40(1)", Amount: money.Baht(600000)},
+				{Category: "40(8)", Amount: money.Baht(400000), ForeignTax: money.Baht(50000)},
+			}},
+			wantTax:           money.Baht(30000),
+			wantForeignCredit: money.Baht(20000),
+		},
+		{
+			name: "foreign tax below the attributable Thai tax should be credited in full",
+			in: Input{Incomes: []Income{
+				{Category: "40(1)", Amount: money.Baht(600000)},
+				{Category: "40(8)", Amount: money.Baht(400000), ForeignTax: money.Baht(5000)},
+			}},
+			wantTax:           money.Baht(45000),
+			wantForeignCredit: money.Baht(5000),
+		},
+	}
+
+	for _, tCase := range tt {
+		t.Run(tCase.name, func(t *testing.T) {
+			got, err := New(setting).Calculate(tCase.in)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got.Tax != tCase.wantTax || got.DividendWht != tCase.wantDividendWht || got.DividendCredit != tCase.wantDividendCredit || got.ForeignTaxCredit != tCase.wantForeignCredit {
+				t.Errorf("expected %v %v %v %v but got %v %v %v %v", tCase.wantTax, tCase.wantDividendWht, tCase.wantDividendCredit, tCase.wantForeignCredit, got.Tax, got.DividendWht, got.DividendCredit, got.ForeignTaxCredit)
+			}
+		})
+	}
+}
+
+func TestValidateCredits(t *testing.T) {
+	tt := []struct {
+		name    string
+		in      Input
+		wantErr string
+	}{
+		{
+			name:    "corporate rate above 30",
+			in:      Input{Dividends: []Dividend{{Amount: money.Baht(1000), CorporateRate: 35}}},
+			wantErr: "CorporateRate must be in the range 0 to 30.",
+		},
+		{
+			name:    "dividend wht above the dividend",
+			in:      Input{Dividends: []Dividend{{Amount: money.Baht(1000), Wht: money.Baht(1001)}}},
+			wantErr: "Wht of dividend must be in the range 0 to Amount.",
+		},
+		{
+			name:    "foreign tax above the income",
+			in:      Input{Incomes: []Income{{Category: "40(8)", Amount: money.Baht(1000), ForeignTax: money.Baht(1001)}}},
+			wantErr: "ForeignTax must be in the range 0 to Amount income.",
+		},
+	}
+
+	for _, tCase := range tt {
+		t.Run(tCase.name, func(t *testing.T) {
+			err := Validate(tCase.in)
+			if err == nil || err.Error() != tCase.wantErr {
+				t.Errorf("expected error %q but got %v", tCase.wantErr, err)
+			}
+		})
+	}
+}
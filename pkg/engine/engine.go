@@ -0,0 +1,330 @@
+package engine
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"github.com/thosaphol/assessment-tax/pkg/money"
+)
+
+// Setting holds the admin configured deductions used by the calculation.
+type Setting struct {
+	// Version identifies the stored settings, 0 when they are not stored.
+	Version     int64
+	Personal    money.Money
+	MaxKReceipt money.Money
+	Household   HouseholdDeduction
+	// TaxConsts overrides the built-in bracket table of a tax year.
+	TaxConsts map[int][]TaxConst
+
+	halfYear bool
+}
+
+// Input is the income and deductions of one taxpayer for a tax year.
+type Input struct {
+	TaxYear int
+	Period  string
+	// TotalIncome is income without expense deduction.
+	TotalIncome money.Money
+	// Incomes are income by category, the expense of which is deducted before allowances.
+	Incomes []Income
+	Wht     money.Money
+	// PrepaidHalfYearTax is the tax paid with the half-year return, credited like Wht.
+	PrepaidHalfYearTax money.Money
+	Dividends          []Dividend
+	// IncludeDividends taxes the dividends, otherwise their withholding is the final tax.
+	IncludeDividends bool
+	// LumpSum is taxed separately and added to the tax.
+	LumpSum    *LumpSum
+	Allowances []Allowance
+	Household  Household
+	// FilingDate assesses filing after the due date.
+	FilingDate time.Time
+	// PaymentDate is given when the tax is paid after FilingDate.
+	PaymentDate time.Time
+	// allowed replaces Allowances with amounts already capped, for a joint return.
+	allowed []AllowanceResult
+}
+
+// TaxLevel has the part of the net income taxed at Rate in Taxable.
+type TaxLevel struct {
+	Level   string
+	Rate    int
+	Taxable money.Money
+	Tax     money.Money
+}
+
+// Result is the tax calculated for an Input.
+type Result struct {
+	// SettingsVersion is the Version of the setting calculated with.
+	SettingsVersion int64
+	TaxYear         int
+	Period          string
+	TotalIncome     money.Money
+	Incomes         []IncomeResult
+	Allowances      []AllowanceResult
+	NetIncome       money.Money
+	// UnusedDeduction is the part of the deductions above the income, which saves no tax.
+	UnusedDeduction money.Money
+	// MarginalRate is the rate of the bracket the net income falls into.
+	MarginalRate int
+	// Headroom is the net income left before the next bracket, unlimited in the top one.
+	Headroom money.Money
+	// EffectiveRate is the tax before credits as a percent of the income.
+	EffectiveRate float64
+	// EffectiveRateOnNet is the tax before credits as a percent of the net income.
+	EffectiveRateOnNet float64
+	// ProgressiveTax and GrossIncomeTax are the tax of each method before credits.
+	ProgressiveTax money.Money
+	GrossIncomeTax money.Money
+	// GrossIncomeApplied tells whether the gross income method had to be compared.
+	GrossIncomeApplied bool
+	TaxMethod          string
+	// Tax is owed after the credits, with the tax of LumpSum.
+	Tax       money.Money
+	TaxRefund money.Money
+	// IncomeWht is the withholding of Incomes, credited like Wht.
+	IncomeWht money.Money
+	// DividendWht is the withholding of the dividends taxed, credited like Wht.
+	DividendWht money.Money
+	// DividendCredit is the corporate tax credited on the dividends taxed.
+	DividendCredit money.Money
+	// ForeignTaxCredit is the tax paid abroad credited against the tax.
+	ForeignTaxCredit money.Money
+	// FinalTaxItems are the incomes left out of the return as final tax.
+	FinalTaxItems []FinalTaxItem
+	// FinalTax sums the withholding of FinalTaxItems.
+	FinalTax money.Money
+	// LumpSum is taxed separately, left out of the rates and tax levels.
+	LumpSum   *LumpSumResult
+	TaxLevels []TaxLevel
+	// DueDate is set only when a filing date is given.
+	DueDate   time.Time
+	Surcharge money.Money
+	Penalty   money.Money
+	// TotalPayable is Tax with the Surcharge and Penalty of filing late.
+	TotalPayable money.Money
+}
+
+// Engine calculates personal income tax without any HTTP or storage dependency.
+type Engine struct {
+	setting Setting
+}
+
+func New(s Setting) *Engine {
+	return &Engine{setting: s}
+}
+
+// Setting returns the setting the engine calculates with.
+func (e *Engine) Setting() Setting {
+	return e.setting
+}
+
+func (e *Engine) Calculate(in Input) (Result, error) {
+	err := Validate(in)
+	if err != nil {
+		return Result{}, err
+	}
+
+	year := ResolveTaxYear(in.TaxYear)
+	tConsts, err := e.TaxConsts(year)
+	if err != nil {
+		return Result{}, err
+	}
+
+	period := ResolvePeriod(in.Period)
+	s := e.setting
+	if period == PeriodHalfYear {
+		in = halfYearInput(in)
+		s = halfYearSetting(s)
+	}
+	in, finalItems := withFinalTax(in)
+	in, divWht, divCredit := withDividends(in)
+
+	incomes := calculateIncomes(in.Incomes)
+	income := in.TotalIncome.Add(sumNetIncomes(incomes))
+
+	hhTotal, hhResults := calculateHousehold(in.Household, s.Household)
+	alwTotal, alwResults := calculateAllowance(in.Allowances, grossIncome(in), income.Sub(hhTotal), s)
+	if in.allowed != nil {
+		alwTotal, alwResults = sumAllowed(in.allowed)
+	}
+	iNet := calculateIncome(income, hhTotal.Add(alwTotal), s.Personal)
+	unused := money.Max(money.Sum(s.Personal, hhTotal, alwTotal).Sub(income), money.Zero)
+	alwResults = append(hhResults, alwResults...)
+	markUnused(alwResults, unused)
+
+	ptax, tLevels := calculateTaxLevels(iNet, tConsts)
+	gtax, applied := calculateGrossIncomeTax(in.Incomes)
+	ttax, method := chooseTaxMethod(ptax, gtax, applied)
+
+	r := Result{
+		SettingsVersion:    e.setting.Version,
+		TaxYear:            year,
+		Period:             period,
+		TotalIncome:        grossIncome(in),
+		Incomes:            incomes,
+		Allowances:         alwResults,
+		NetIncome:          iNet,
+		UnusedDeduction:    unused,
+		EffectiveRate:      percentOf(ttax, grossIncome(in)),
+		EffectiveRateOnNet: percentOf(ttax, iNet),
+		ProgressiveTax:     ptax,
+		GrossIncomeTax:     gtax,
+		GrossIncomeApplied: applied,
+		TaxMethod:          method,
+		IncomeWht:          sumIncomeWht(in.Incomes),
+		DividendWht:        divWht,
+		DividendCredit:     divCredit,
+		ForeignTaxCredit:   foreignTaxCredit(in.Incomes, ttax, grossIncome(in)),
+		FinalTaxItems:      finalItems,
+		FinalTax:           sumFinalTax(finalItems),
+		LumpSum:            calculateLumpSum(in.LumpSum, tConsts),
+		TaxLevels:          tLevels,
+	}
+	r.MarginalRate, r.Headroom = marginalRate(iNet, tConsts)
+	credit := money.Sum(in.Wht, in.PrepaidHalfYearTax, r.IncomeWht, r.DividendWht, r.DividendCredit, r.ForeignTaxCredit)
+	total := ttax
+	if r.LumpSum != nil {
+		total = total.Add(r.LumpSum.Tax)
+		credit = credit.Add(r.LumpSum.Wht)
+	}
+	if !total.LessThan(credit) {
+		r.Tax = total.Sub(credit)
+	} else {
+		r.TaxRefund = credit.Sub(total)
+	}
+	if !in.FilingDate.IsZero() {
+		r.DueDate = DueDate(year, period)
+		r.Surcharge, r.Penalty = calculateLate(r.Tax, r.DueDate, in.FilingDate, in.PaymentDate)
+		r.TotalPayable = money.Sum(r.Tax, r.Surcharge, r.Penalty)
+	}
+	return r, nil
+}
+
+func (e *Engine) TaxConsts(year int) ([]TaxConst, error) {
+	if tConsts, ok := e.setting.TaxConsts[ResolveTaxYear(year)]; ok {
+		return tConsts, nil
+	}
+	return GetTaxConsts(year)
+}
+
+func Validate(in Input) error {
+	err := validatePeriod(in)
+	if err != nil {
+		return err
+	}
+	err = validateDates(in)
+	if err != nil {
+		return err
+	}
+	err = validateAllowance(in.Allowances)
+	if err != nil {
+		return err
+	}
+	err = validateHousehold(in.Household)
+	if err != nil {
+		return err
+	}
+	err = validateIncome(in.TotalIncome)
+	if err != nil {
+		return err
+	}
+	err = validateIncomes(in.Incomes)
+	if err != nil {
+		return err
+	}
+	err = validateCredits(in)
+	if err != nil {
+		return err
+	}
+	err = validateLumpSum(in.LumpSum)
+	if err != nil {
+		return err
+	}
+
+	err = validateWht(grossIncome(in), in.Wht)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func grossIncome(in Input) money.Money {
+	return in.TotalIncome.Add(sumIncomes(in.Incomes))
+}
+
+func validateWht(income, wht money.Money) error {
+	if wht.IsNegative() || wht.GreaterThan(income) {
+		return errors.New("Wht must be in the range 0 to TotalIncome.")
+	}
+	return nil
+}
+
+func validateIncome(income money.Money) error {
+	if income.IsNegative() {
+		return errors.New("TotalIncome must have a starting value of 0.")
+	}
+	return nil
+}
+
+func calculateTaxLevels(iNet money.Money, tConsts []TaxConst) (money.Money, []TaxLevel) {
+	var tLevels []TaxLevel
+	ttax := money.Zero
+	for _, tConst := range tConsts {
+		var tLevel = TaxLevel{Level: tConst.Level, Rate: tConst.TaxRate}
+		if iNet.GreaterThan(tConst.Lower) {
+			tLevel.Taxable = money.Min(iNet, tConst.Upper).Sub(tConst.Lower)
+			tLevel.Tax = tLevel.Taxable.Percent(tConst.TaxRate)
+			ttax = ttax.Add(tLevel.Tax)
+		}
+		tLevels = append(tLevels, tLevel)
+	}
+	return ttax, tLevels
+}
+
+// marginalRate returns the rate of the bracket of the net income and the
+// income left to its upper bound.
+func marginalRate(iNet money.Money, tConsts []TaxConst) (int, money.Money) {
+	for _, tConst := range tConsts {
+		if iNet.GreaterThan(tConst.Upper) {
+			continue
+		}
+		if tConst.Upper.IsUnlimited() {
+			return tConst.TaxRate, money.Unlimited
+		}
+		return tConst.TaxRate, tConst.Upper.Sub(money.Max(iNet, money.Zero))
+	}
+	return 0, money.Unlimited
+}
+
+// percentOf returns tax as a percent of base to 2 decimal places.
+func percentOf(tax, base money.Money) float64 {
+	if !base.GreaterThan(money.Zero) {
+		return 0
+	}
+	return math.Round(float64(tax.Satang())*10000/float64(base.Satang())) / 100
+}
+
+// markUnused marks the deductions applied last as unused, up to unused in
+// total. The personal deduction is applied first.
+func sumAllowed(allowed []AllowanceResult) (money.Money, []AllowanceResult) {
+	total := money.Zero
+	for _, r := range allowed {
+		total = total.Add(r.Allowed)
+	}
+	return total, allowed
+}
+
+func markUnused(results []AllowanceResult, unused money.Money) {
+	for i := len(results) - 1; i >= 0 && unused.GreaterThan(money.Zero); i-- {
+		results[i].Unused = money.Min(results[i].Allowed, unused)
+		unused = unused.Sub(results[i].Unused)
+	}
+}
+
+// calculateIncome returns the net income, not below 0.
+func calculateIncome(income, totalAlw, personalDed money.Money) money.Money {
+	return money.Max(income.Sub(personalDed).Sub(totalAlw), money.Zero)
+}
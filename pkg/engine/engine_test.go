@@ -0,0 +1,399 @@
+package engine
+
+import (
+	"github.com/thosaphol/assessment-tax/pkg/money"
+	"reflect"
+	"testing"
+)
+
+var setting = Setting{Personal: money.Baht(60000), MaxKReceipt: money.Baht(50000)}
+
+func moneyPtr(f float64) *money.Money {
+	m := money.Baht(f)
+	return &m
+}
+
+func TestCalculate(t *testing.T) {
+	tt := []struct {
+		name string
+		in   Input
+		want Result
+	}{
+		{
+			name: "tax 29,000 when income is 500,000",
+			in:   Input{TotalIncome: money.Baht(500000)},
+			want: Result{TaxYear: 2567, Period: PeriodAnnual, TotalIncome: money.Baht(500000), NetIncome: money.Baht(440000), MarginalRate: 10, Headroom: money.Baht(60000), EffectiveRate: 5.8, EffectiveRateOnNet: 6.59, ProgressiveTax: money.Baht(29000), TaxMethod: MethodProgressive, Tax: money.Baht(29000), TaxLevels: []TaxLevel{
+				{Tax: money.Baht(0), Level: "0-150,000", Rate: 0, Taxable: money.Baht(150000)},
+				{Tax: money.Baht(29000), Level: "150,001-500,000", Rate: 10, Taxable: money.Baht(290000)},
+				{Tax: money.Baht(0), Level: "500,001-1,000,000", Rate: 15, Taxable: money.Baht(0)},
+				{Tax: money.Baht(0), Level: "1,000,001-2,000,000", Rate: 20, Taxable: money.Baht(0)},
+				{Tax: money.Baht(0), Level: "2,000,001 ขึ้นไป", Rate: 35, Taxable: money.Baht(0)},
+			}},
+		},
+		{
+			name: "tax 20,100 when income is 500,000, k-receipt is 200,000, donation is 100,000 capped at 39,000",
+			in: Input{TotalIncome: money.Baht(500000), Allowances: []Allowance{
+				{AllowanceType: "k-receipt", Amount: money.Baht(200000)},
+				{AllowanceType: "donation", Amount: money.Baht(100000)},
+			}},
+			want: Result{TaxYear: 2567, Period: PeriodAnnual, TotalIncome: money.Baht(500000), Allowances: []AllowanceResult{
+				{AllowanceType: "k-receipt", Claimed: money.Baht(200000), Allowed: money.Baht(50000)},
+				{AllowanceType: "donation", Claimed: money.Baht(100000), Allowed: money.Baht(39000), Cap: moneyPtr(39000)},
+			}, NetIncome: money.Baht(351000), MarginalRate: 10, Headroom: money.Baht(149000), EffectiveRate: 4.02, EffectiveRateOnNet: 5.73, ProgressiveTax: money.Baht(20100), TaxMethod: MethodProgressive, Tax: money.Baht(20100), TaxLevels: []TaxLevel{
+				{Tax: money.Baht(0), Level: "0-150,000", Rate: 0, Taxable: money.Baht(150000)},
+				{Tax: money.Baht(20100), Level: "150,001-500,000", Rate: 10, Taxable: money.Baht(201000)},
+				{Tax: money.Baht(0), Level: "500,001-1,000,000", Rate: 15, Taxable: money.Baht(0)},
+				{Tax: money.Baht(0), Level: "1,000,001-2,000,000", Rate: 20, Taxable: money.Baht(0)},
+				{Tax: money.Baht(0), Level: "2,000,001 ขึ้นไป", Rate: 35, Taxable: money.Baht(0)},
+			}},
+		},
+		{
+			name: "tax 29,000 when income is 500,000 in tax year 2565",
+			in:   Input{TaxYear: 2565, TotalIncome: money.Baht(500000)},
+			want: Result{TaxYear: 2565, Period: PeriodAnnual, TotalIncome: money.Baht(500000), NetIncome: money.Baht(440000), MarginalRate: 10, Headroom: money.Baht(60000), EffectiveRate: 5.8, EffectiveRateOnNet: 6.59, ProgressiveTax: money.Baht(29000), TaxMethod: MethodProgressive, Tax: money.Baht(29000), TaxLevels: []TaxLevel{
+				{Tax: money.Baht(0), Level: "0-150,000", Rate: 0, Taxable: money.Baht(150000)},
+				{Tax: money.Baht(29000), Level: "150,001-500,000", Rate: 10, Taxable: money.Baht(290000)},
+				{Tax: money.Baht(0), Level: "500,001-1,000,000", Rate: 15, Taxable: money.Baht(0)},
+				{Tax: money.Baht(0), Level: "1,000,001-2,000,000", Rate: 20, Taxable: money.Baht(0)},
+				{Tax: money.Baht(0), Level: "2,000,001 ขึ้นไป", Rate: 35, Taxable: money.Baht(0)},
+			}},
+		},
+		{
+			name: "refund 5,000 when income is 560,000, wht is 40,000",
+			in:   Input{TotalIncome: money.Baht(560000), Wht: money.Baht(40000)},
+			want: Result{TaxYear: 2567, Period: PeriodAnnual, TotalIncome: money.Baht(560000), NetIncome: money.Baht(500000), MarginalRate: 10, Headroom: money.Baht(0), EffectiveRate: 6.25, EffectiveRateOnNet: 7, ProgressiveTax: money.Baht(35000), TaxMethod: MethodProgressive, Tax: money.Baht(0), TaxRefund: money.Baht(5000), TaxLevels: []TaxLevel{
+				{Tax: money.Baht(0), Level: "0-150,000", Rate: 0, Taxable: money.Baht(150000)},
+				{Tax: money.Baht(35000), Level: "150,001-500,000", Rate: 10, Taxable: money.Baht(350000)},
+				{Tax: money.Baht(0), Level: "500,001-1,000,000", Rate: 15, Taxable: money.Baht(0)},
+				{Tax: money.Baht(0), Level: "1,000,001-2,000,000", Rate: 20, Taxable: money.Baht(0)},
+				{Tax: money.Baht(0), Level: "2,000,001 ขึ้นไป", Rate: 35, Taxable: money.Baht(0)},
+			}},
+		},
+	}
+
+	for _, tCase := range tt {
+		t.Run(tCase.name, func(t *testing.T) {
+			got, err := New(setting).Calculate(tCase.in)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tCase.want) {
+				t.Errorf("expected %v but got %v", tCase.want, got)
+			}
+		})
+	}
+}
+
+func TestCalculateValidation(t *testing.T) {
+	tt := []struct {
+		name    string
+		in      Input
+		wantErr string
+	}{
+		{
+			name:    "given negative allowance should return error",
+			in:      Input{Allowances: []Allowance{{AllowanceType: "donation", Amount: money.Baht(-1)}}},
+			wantErr: "Amount allowance must greater than 0.",
+		},
+		{
+			name:    "given unknown allowance type should return error",
+			in:      Input{Allowances: []Allowance{{AllowanceType: "qwerty"}}},
+			wantErr: "AllowanceType 'qwerty' is not supported.",
+		},
+		{
+			name:    "given unknown period should return error",
+			in:      Input{Period: "quarter"},
+			wantErr: "Period must be annual or half-year.",
+		},
+		{
+			name:    "given negative prepaid half-year tax should return error",
+			in:      Input{PrepaidHalfYearTax: money.Baht(-1)},
+			wantErr: "PrepaidHalfYearTax must have a starting value of 0.",
+		},
+		{
+			name:    "given prepaid half-year tax in half-year period should return error",
+			in:      Input{Period: PeriodHalfYear, PrepaidHalfYearTax: money.Baht(1)},
+			wantErr: "PrepaidHalfYearTax is only credited in the annual period.",
+		},
+		{
+			name:    "given total income in half-year period should return error",
+			in:      Input{Period: PeriodHalfYear, TotalIncome: money.Baht(1000000)},
+			wantErr: "TotalIncome is only counted in the annual period.",
+		},
+		{
+			name:    "given dividends in half-year period should return error",
+			in:      Input{Period: PeriodHalfYear, Dividends: []Dividend{{Amount: money.Baht(100000)}}},
+			wantErr: "Dividends are only counted in the annual period.",
+		},
+		{
+			name:    "given lump sum in half-year period should return error",
+			in:      Input{Period: PeriodHalfYear, LumpSum: &LumpSum{Amount: money.Baht(500000), YearsOfService: 10}},
+			wantErr: "LumpSum is only taxed in the annual period.",
+		},
+		{
+			name:    "given unsupported tax year should return error",
+			in:      Input{TaxYear: 2500},
+			wantErr: "TaxYear is not supported.",
+		},
+		{
+			name:    "given negative income should return error",
+			in:      Input{TotalIncome: money.Baht(-1)},
+			wantErr: "TotalIncome must have a starting value of 0.",
+		},
+		{
+			name:    "given wht greater than income should return error",
+			in:      Input{TotalIncome: money.Baht(100), Wht: money.Baht(1000)},
+			wantErr: "Wht must be in the range 0 to TotalIncome.",
+		},
+	}
+
+	for _, tCase := range tt {
+		t.Run(tCase.name, func(t *testing.T) {
+			_, err := New(setting).Calculate(tCase.in)
+			if err == nil || err.Error() != tCase.wantErr {
+				t.Errorf("expected error %q but got %v", tCase.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestCalculateHalfYear(t *testing.T) {
+	in := Input{
+		Period: PeriodHalfYear,
+		Incomes: []Income{
+			{Category: "40(1)", Amount: money.Baht(300000)},
+			{Category: "40(8)", Amount: money.Baht(600000)},
+		},
+		Allowances: []Allowance{
+			{AllowanceType: "rmf", Amount: money.Baht(50000)},
+			{AllowanceType: "life-insurance", Amount: money.Baht(80000)},
+		},
+	}
+
+	got, err := New(setting).Calculate(in)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	wantAllowances := []AllowanceResult{
+		{AllowanceType: "rmf", Claimed: money.Baht(50000), Allowed: money.Baht(0)},
+		{AllowanceType: "life-insurance", Claimed: money.Baht(80000), Allowed: money.Baht(50000)},
+	}
+	if got.Period != PeriodHalfYear || got.TotalIncome != money.Baht(600000) || got.NetIncome != money.Baht(160000) || got.Tax != money.Baht(1000) {
+		t.Errorf("expected half-year income 600000.00 net 160000.00 tax 1000.00 but got %v income %v net %v tax %v", got.Period, got.TotalIncome, got.NetIncome, got.Tax)
+	}
+	if !reflect.DeepEqual(got.Allowances, wantAllowances) {
+		t.Errorf("expected %v but got %v", wantAllowances, got.Allowances)
+	}
+}
+
+func TestCalculatePrepaidHalfYearTax(t *testing.T) {
+	tt := []struct {
+		name          string
+		in            Input
+		wantTax       money.Money
+		wantTaxRefund money.Money
+	}{
+		{
+			name:    "prepaid 1,000 should be credited with wht",
+			in:      Input{TotalIncome: money.Baht(500000), Wht: money.Baht(8000), PrepaidHalfYearTax: money.Baht(1000)},
+			wantTax: money.Baht(20000),
+		},
+		{
+			name:          "prepaid over the tax should be refunded",
+			in:            Input{TotalIncome: money.Baht(500000), PrepaidHalfYearTax: money.Baht(30000)},
+			wantTaxRefund: money.Baht(1000),
+		},
+	}
+
+	for _, tCase := range tt {
+		t.Run(tCase.name, func(t *testing.T) {
+			got, err := New(setting).Calculate(tCase.in)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got.Tax != tCase.wantTax || got.TaxRefund != tCase.wantTaxRefund {
+				t.Errorf("expected tax %v refund %v but got tax %v refund %v", tCase.wantTax, tCase.wantTaxRefund, got.Tax, got.TaxRefund)
+			}
+		})
+	}
+}
+
+func TestCalculateWithSettingTaxConsts(t *testing.T) {
+	s := setting
+	s.Version = 3
+	s.TaxConsts = map[int][]TaxConst{
+		2569: {
+			NewTaxConst(money.Baht(0), money.Baht(100000), 0),
+			NewTaxConst(money.Baht(100000), money.Unlimited, 10),
+		},
+	}
+	want := Result{SettingsVersion: 3, TaxYear: 2569, Period: PeriodAnnual, TotalIncome: money.Baht(500000), NetIncome: money.Baht(440000), MarginalRate: 10, Headroom: money.Unlimited, EffectiveRate: 6.8, EffectiveRateOnNet: 7.73, ProgressiveTax: money.Baht(34000), TaxMethod: MethodProgressive, Tax: money.Baht(34000), TaxLevels: []TaxLevel{
+		{Tax: money.Baht(0), Level: "0-100,000", Rate: 0, Taxable: money.Baht(100000)},
+		{Tax: money.Baht(34000), Level: "100,001 ขึ้นไป", Rate: 10, Taxable: money.Baht(340000)},
+	}}
+
+	got, err := New(s).Calculate(Input{TaxYear: 2569, TotalIncome: money.Baht(500000)})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v but got %v", want, got)
+	}
+}
+
+func TestValidateTaxConsts(t *testing.T) {
+	tt := []struct {
+		name    string
+		tConsts []TaxConst
+		wantErr string
+	}{
+		{
+			name:    "given built-in tax brackets should return no error",
+			tConsts: brackets2560,
+		},
+		{
+			name:    "given empty tax brackets should return error",
+			wantErr: "Tax brackets must have at least 1 level.",
+		},
+		{
+			name: "given first lower is not 0 should return error",
+			tConsts: []TaxConst{
+				NewTaxConst(money.Baht(100), money.Unlimited, 0),
+			},
+			wantErr: "Lower of the first tax bracket must be 0.",
+		},
+		{
+			name: "given overlap tax brackets should return error",
+			tConsts: []TaxConst{
+				NewTaxConst(money.Baht(0), money.Baht(150000), 0),
+				NewTaxConst(money.Baht(100000), money.Unlimited, 10),
+			},
+			wantErr: "Tax bracket 2 overlaps the previous bracket.",
+		},
+		{
+			name: "given gap between tax brackets should return error",
+			tConsts: []TaxConst{
+				NewTaxConst(money.Baht(0), money.Baht(150000), 0),
+				NewTaxConst(money.Baht(200000), money.Unlimited, 10),
+			},
+			wantErr: "Tax bracket 2 is not contiguous with the previous bracket.",
+		},
+		{
+			name: "given decreasing tax rate should return error",
+			tConsts: []TaxConst{
+				NewTaxConst(money.Baht(0), money.Baht(150000), 10),
+				NewTaxConst(money.Baht(150000), money.Unlimited, 5),
+			},
+			wantErr: "TaxRate of tax bracket 2 must be greater than the previous bracket.",
+		},
+		{
+			name: "given bounded last tax bracket should return error",
+			tConsts: []TaxConst{
+				NewTaxConst(money.Baht(0), money.Baht(150000), 0),
+				NewTaxConst(money.Baht(150000), money.Baht(500000), 10),
+			},
+			wantErr: "Upper of the last tax bracket must be unbounded.",
+		},
+	}
+
+	for _, tCase := range tt {
+		t.Run(tCase.name, func(t *testing.T) {
+			err := ValidateTaxConsts(tCase.tConsts)
+			if tCase.wantErr == "" && err != nil {
+				t.Errorf("expected no error but got %v", err)
+			}
+			if tCase.wantErr != "" && (err == nil || err.Error() != tCase.wantErr) {
+				t.Errorf("expected error %q but got %v", tCase.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestCalculateHousehold(t *testing.T) {
+	d := HouseholdDeduction{
+		Spouse:     money.Baht(60000),
+		Child:      money.Baht(30000),
+		ChildBonus: money.Baht(60000),
+		Parent:     money.Baht(30000),
+		Disability: money.Baht(60000),
+	}
+	tt := []struct {
+		name        string
+		h           Household
+		want        money.Money
+		wantResults []AllowanceResult
+	}{
+		{
+			name: "no household should have no allowance",
+		},
+		{
+			name: "second child born since 2561 should get bonus",
+			h:    Household{Spouse: true, Children: []Child{{BirthYear: 2560}, {BirthYear: 2562}, {BirthYear: 2565}}},
+			want: money.Baht(210000),
+			wantResults: []AllowanceResult{
+				{AllowanceType: "spouse", Claimed: money.Baht(60000), Allowed: money.Baht(60000)},
+				{AllowanceType: "child", Claimed: money.Baht(150000), Allowed: money.Baht(150000)},
+			},
+		},
+		{
+			name: "first child born since 2561 should not get bonus",
+			h:    Household{Children: []Child{{BirthYear: 2562}}, Parents: 2, DisabledDependents: 1},
+			want: money.Baht(150000),
+			wantResults: []AllowanceResult{
+				{AllowanceType: "child", Claimed: money.Baht(30000), Allowed: money.Baht(30000)},
+				{AllowanceType: "parent", Claimed: money.Baht(60000), Allowed: money.Baht(60000)},
+				{AllowanceType: "disability", Claimed: money.Baht(60000), Allowed: money.Baht(60000)},
+			},
+		},
+	}
+
+	for _, tCase := range tt {
+		t.Run(tCase.name, func(t *testing.T) {
+			got, gotResults := calculateHousehold(tCase.h, d)
+			if got != tCase.want {
+				t.Errorf("expected %v but got %v", tCase.want, got)
+			}
+			if !reflect.DeepEqual(gotResults, tCase.wantResults) {
+				t.Errorf("expected %v but got %v", tCase.wantResults, gotResults)
+			}
+		})
+	}
+}
+
+func TestMarkUnused(t *testing.T) {
+	results := []AllowanceResult{
+		{AllowanceType: "spouse", Allowed: money.Baht(60000)},
+		{AllowanceType: "k-receipt", Allowed: money.Baht(50000)},
+		{AllowanceType: "donation", Allowed: money.Baht(0)},
+	}
+	want := []AllowanceResult{
+		{AllowanceType: "spouse", Allowed: money.Baht(60000), Unused: money.Baht(20000)},
+		{AllowanceType: "k-receipt", Allowed: money.Baht(50000), Unused: money.Baht(50000)},
+		{AllowanceType: "donation", Allowed: money.Baht(0)},
+	}
+
+	markUnused(results, money.Baht(70000))
+	if !reflect.DeepEqual(results, want) {
+		t.Errorf("expected %v but got %v", want, results)
+	}
+}
+
+func TestCalculateIncome(t *testing.T) {
+	tt := []struct {
+		name string
+		in   money.Money
+		want money.Money
+	}{
+		{name: "net income after the deductions", in: money.Baht(500000), want: money.Baht(390000)},
+		{name: "net income should not be below 0 when the deductions exceed the income", in: money.Baht(50000), want: money.Baht(0)},
+	}
+
+	for _, tCase := range tt {
+		t.Run(tCase.name, func(t *testing.T) {
+			got := calculateIncome(tCase.in, money.Baht(50000), money.Baht(60000))
+			if got != tCase.want {
+				t.Errorf("expected %v but got %v", tCase.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,252 @@
+package engine
+
+import (
+	"fmt"
+
+	"github.com/thosaphol/assessment-tax/pkg/money"
+)
+
+const (
+	StepIncome       = "income"
+	StepExpense      = "expense"
+	StepPersonal     = "personal"
+	StepAllowance    = "allowance"
+	StepNetIncome    = "net-income"
+	StepLevel        = "level"
+	StepMethod       = "method"
+	StepLumpSum      = "lump-sum"
+	StepLumpSumWht   = "lump-sum-wht"
+	StepWht          = "wht"
+	StepPrepaid      = "prepaid-half-year-tax"
+	StepIncomeWht    = "income-wht"
+	StepDividendWht  = "dividend-wht"
+	StepDividend     = "dividend-credit"
+	StepForeignTax   = "foreign-tax-credit"
+	StepTax          = "tax"
+	StepTaxRefund    = "tax-refund"
+	StepSurcharge    = "surcharge"
+	StepPenalty      = "penalty"
+	StepTotalPayable = "total-payable"
+)
+
+// Step is one line of how a Result was derived, described in English and
+// in Thai.
+type Step struct {
+	Step   string
+	Amount money.Money
+	En     string
+	Th     string
+}
+
+// Explain lists the steps from the income of in to r, which is the result of
+// calculating in.
+func (e *Engine) Explain(in Input, r Result) []Step {
+	s := e.setting
+	if r.Period == PeriodHalfYear {
+		s = halfYearSetting(s)
+	}
+
+	steps := []Step{{
+		Step:   StepIncome,
+		Amount: r.TotalIncome,
+		En:     fmt.Sprintf("Total income %s", formatAmount(r.TotalIncome)),
+		Th:     fmt.Sprintf("เงินได้พึงประเมินรวม %s", formatAmount(r.TotalIncome)),
+	}}
+	for _, inc := range r.Incomes {
+		steps = append(steps, Step{
+			Step:   StepExpense,
+			Amount: inc.Expense,
+			En:     fmt.Sprintf("Less expense of %s income %s: %s", inc.Category, formatAmount(inc.Amount), formatAmount(inc.Expense)),
+			Th:     fmt.Sprintf("หักค่าใช้จ่ายเงินได้ %s จำนวน %s: %s", inc.Category, formatAmount(inc.Amount), formatAmount(inc.Expense)),
+		})
+	}
+	steps = append(steps, Step{
+		Step:   StepPersonal,
+		Amount: s.Personal,
+		En:     fmt.Sprintf("Less personal deduction %s", formatAmount(s.Personal)),
+		Th:     fmt.Sprintf("หักค่าลดหย่อนส่วนตัว %s", formatAmount(s.Personal)),
+	})
+	for _, alw := range r.Allowances {
+		steps = append(steps, allowanceStep(alw))
+	}
+	steps = append(steps, Step{
+		Step:   StepNetIncome,
+		Amount: r.NetIncome,
+		En:     fmt.Sprintf("Net income %s", formatAmount(r.NetIncome)),
+		Th:     fmt.Sprintf("เงินได้สุทธิ %s", formatAmount(r.NetIncome)),
+	})
+	for _, l := range r.TaxLevels {
+		if l.Taxable.IsZero() {
+			continue
+		}
+		steps = append(steps, Step{
+			Step:   StepLevel,
+			Amount: l.Tax,
+			En:     fmt.Sprintf("Level %s: %s at %d%% = %s", l.Level, formatAmount(l.Taxable), l.Rate, formatAmount(l.Tax)),
+			Th:     fmt.Sprintf("ขั้น %s: %s อัตรา %d%% = %s", l.Level, formatAmount(l.Taxable), l.Rate, formatAmount(l.Tax)),
+		})
+	}
+	if r.GrossIncomeApplied {
+		steps = append(steps, methodStep(r))
+	}
+	if r.LumpSum != nil {
+		steps = append(steps, lumpSumSteps(*r.LumpSum)...)
+	}
+	if !in.Wht.IsZero() {
+		steps = append(steps, Step{
+			Step:   StepWht,
+			Amount: in.Wht,
+			En:     fmt.Sprintf("Less withholding tax %s", formatAmount(in.Wht)),
+			Th:     fmt.Sprintf("หักภาษีหัก ณ ที่จ่าย %s", formatAmount(in.Wht)),
+		})
+	}
+	if !in.PrepaidHalfYearTax.IsZero() {
+		steps = append(steps, Step{
+			Step:   StepPrepaid,
+			Amount: in.PrepaidHalfYearTax,
+			En:     fmt.Sprintf("Less tax paid with the half-year return %s", formatAmount(in.PrepaidHalfYearTax)),
+			Th:     fmt.Sprintf("หักภาษีที่ชำระไว้ตามแบบ ภ.ง.ด.94 %s", formatAmount(in.PrepaidHalfYearTax)),
+		})
+	}
+	steps = append(steps, creditSteps(r)...)
+	if r.TaxRefund.IsZero() {
+		steps = append(steps, Step{
+			Step:   StepTax,
+			Amount: r.Tax,
+			En:     fmt.Sprintf("Tax payable %s", formatAmount(r.Tax)),
+			Th:     fmt.Sprintf("ภาษีที่ต้องชำระ %s", formatAmount(r.Tax)),
+		})
+	} else {
+		steps = append(steps, Step{
+			Step:   StepTaxRefund,
+			Amount: r.TaxRefund,
+			En:     fmt.Sprintf("Tax refund %s", formatAmount(r.TaxRefund)),
+			Th:     fmt.Sprintf("ภาษีที่ได้รับคืน %s", formatAmount(r.TaxRefund)),
+		})
+	}
+	if !r.DueDate.IsZero() {
+		steps = append(steps, lateSteps(r)...)
+	}
+	return steps
+}
+
+func allowanceStep(alw AllowanceResult) Step {
+	step := Step{
+		Step:   StepAllowance,
+		Amount: alw.Allowed,
+		En:     fmt.Sprintf("Less %s allowance: claimed %s, allowed %s", alw.AllowanceType, formatAmount(alw.Claimed), formatAmount(alw.Allowed)),
+		Th:     fmt.Sprintf("หักค่าลดหย่อน %s: ขอหัก %s หักได้ %s", alw.AllowanceType, formatAmount(alw.Claimed), formatAmount(alw.Allowed)),
+	}
+	if alw.Cap != nil {
+		step.En += fmt.Sprintf(" (capped at %s)", formatAmount(*alw.Cap))
+		step.Th += fmt.Sprintf(" (เพดาน %s)", formatAmount(*alw.Cap))
+	}
+	if !alw.Unused.IsZero() {
+		step.En += fmt.Sprintf(", %s unused", formatAmount(alw.Unused))
+		step.Th += fmt.Sprintf(" ไม่ได้ใช้ %s", formatAmount(alw.Unused))
+	}
+	return step
+}
+
+func creditSteps(r Result) []Step {
+	var steps []Step
+	if !r.IncomeWht.IsZero() {
+		steps = append(steps, Step{
+			Step:   StepIncomeWht,
+			Amount: r.IncomeWht,
+			En:     fmt.Sprintf("Less tax withheld from incomes %s", formatAmount(r.IncomeWht)),
+			Th:     fmt.Sprintf("หักภาษีเงินได้ที่ถูกหัก ณ ที่จ่าย %s", formatAmount(r.IncomeWht)),
+		})
+	}
+	if !r.DividendWht.IsZero() {
+		steps = append(steps, Step{
+			Step:   StepDividendWht,
+			Amount: r.DividendWht,
+			En:     fmt.Sprintf("Less tax withheld from dividends %s", formatAmount(r.DividendWht)),
+			Th:     fmt.Sprintf("หักภาษีเงินปันผลที่ถูกหัก ณ ที่จ่าย %s", formatAmount(r.DividendWht)),
+		})
+	}
+	if !r.DividendCredit.IsZero() {
+		steps = append(steps, Step{
+			Step:   StepDividend,
+			Amount: r.DividendCredit,
+			En:     fmt.Sprintf("Less dividend tax credit %s", formatAmount(r.DividendCredit)),
+			Th:     fmt.Sprintf("หักเครดิตภาษีเงินปันผล %s", formatAmount(r.DividendCredit)),
+		})
+	}
+	if !r.ForeignTaxCredit.IsZero() {
+		steps = append(steps, Step{
+			Step:   StepForeignTax,
+			Amount: r.ForeignTaxCredit,
+			En:     fmt.Sprintf("Less foreign tax credit %s", formatAmount(r.ForeignTaxCredit)),
+			Th:     fmt.Sprintf("หักเครดิตภาษีที่ชำระในต่างประเทศ %s", formatAmount(r.ForeignTaxCredit)),
+		})
+	}
+	return steps
+}
+
+func lumpSumSteps(ls LumpSumResult) []Step {
+	steps := []Step{{
+		Step:   StepLumpSum,
+		Amount: ls.Tax,
+		En: fmt.Sprintf("Lump sum %s taxed separately: less %d years of service %s, less half %s, net %s, tax %s",
+			formatAmount(ls.Amount), ls.YearsOfService, formatAmount(ls.ServiceDeduction), formatAmount(ls.HalfDeduction), formatAmount(ls.NetIncome), formatAmount(ls.Tax)),
+		Th: fmt.Sprintf("เงินได้ที่จ่ายครั้งเดียวเพราะเหตุออกจากงาน %s แยกคำนวณ: หักอายุงาน %d ปี %s หักครึ่งหนึ่ง %s เงินได้สุทธิ %s ภาษี %s",
+			formatAmount(ls.Amount), ls.YearsOfService, formatAmount(ls.ServiceDeduction), formatAmount(ls.HalfDeduction), formatAmount(ls.NetIncome), formatAmount(ls.Tax)),
+	}}
+	if !ls.Wht.IsZero() {
+		steps = append(steps, Step{
+			Step:   StepLumpSumWht,
+			Amount: ls.Wht,
+			En:     fmt.Sprintf("Less tax withheld from the lump sum %s", formatAmount(ls.Wht)),
+			Th:     fmt.Sprintf("หักภาษีเงินได้ที่จ่ายครั้งเดียวที่ถูกหัก ณ ที่จ่าย %s", formatAmount(ls.Wht)),
+		})
+	}
+	return steps
+}
+
+func methodStep(r Result) Step {
+	if r.TaxMethod == MethodGrossIncome {
+		return Step{
+			Step:   StepMethod,
+			Amount: r.GrossIncomeTax,
+			En:     fmt.Sprintf("Gross income method tax %s is higher than progressive tax %s", formatAmount(r.GrossIncomeTax), formatAmount(r.ProgressiveTax)),
+			Th:     fmt.Sprintf("ภาษีคิดจากเงินได้พึงประเมิน 0.5%% %s สูงกว่าภาษีขั้นบันได %s", formatAmount(r.GrossIncomeTax), formatAmount(r.ProgressiveTax)),
+		}
+	}
+	return Step{
+		Step:   StepMethod,
+		Amount: r.ProgressiveTax,
+		En:     fmt.Sprintf("Progressive tax %s is not lower than gross income method tax %s", formatAmount(r.ProgressiveTax), formatAmount(r.GrossIncomeTax)),
+		Th:     fmt.Sprintf("ภาษีขั้นบันได %s ไม่ต่ำกว่าภาษีคิดจากเงินได้พึงประเมิน 0.5%% %s", formatAmount(r.ProgressiveTax), formatAmount(r.GrossIncomeTax)),
+	}
+}
+
+func lateSteps(r Result) []Step {
+	due := r.DueDate.Format("2006-01-02")
+	return []Step{
+		{
+			Step:   StepSurcharge,
+			Amount: r.Surcharge,
+			En:     fmt.Sprintf("Surcharge for paying after %s: %s", due, formatAmount(r.Surcharge)),
+			Th:     fmt.Sprintf("เงินเพิ่มจากการชำระหลัง %s: %s", due, formatAmount(r.Surcharge)),
+		},
+		{
+			Step:   StepPenalty,
+			Amount: r.Penalty,
+			En:     fmt.Sprintf("Fine for filing after %s: %s", due, formatAmount(r.Penalty)),
+			Th:     fmt.Sprintf("ค่าปรับยื่นแบบหลัง %s: %s", due, formatAmount(r.Penalty)),
+		},
+		{
+			Step:   StepTotalPayable,
+			Amount: r.TotalPayable,
+			En:     fmt.Sprintf("Total payable %s", formatAmount(r.TotalPayable)),
+			Th:     fmt.Sprintf("รวมที่ต้องชำระ %s", formatAmount(r.TotalPayable)),
+		},
+	}
+}
+
+// formatAmount formats a non-negative amount as 1,234.50.
+func formatAmount(amount money.Money) string {
+	return fmt.Sprintf("%s.%02d", formatBaht(amount), amount.Satang()%100)
+}
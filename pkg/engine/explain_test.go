@@ -0,0 +1,57 @@
+package engine
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/thosaphol/assessment-tax/pkg/money"
+)
+
+func TestExplain(t *testing.T) {
+	in := Input{
+		Incomes: []Income{{Category: "40(1)", Amount: money.Baht(600000)}},
+		Wht:     money.Baht(40000),
+		Allowances: []Allowance{
+			{AllowanceType: "donation", Amount: money.Baht(200000)},
+		},
+	}
+	want := []Step{
+		{Step: StepIncome, Amount: money.Baht(600000), En: "Total income 600,000.00", Th: "เงินได้พึงประเมินรวม 600,000.00"},
+		{Step: StepExpense, Amount: money.Baht(100000), En: "Less expense of 40(1) income 600,000.00: 100,000.00", Th: "หักค่าใช้จ่ายเงินได้ 40(1) จำนวน 600,000.00: 100,000.00"},
+		{Step: StepPersonal, Amount: money.Baht(60000), En: "Less personal deduction 60,000.00", Th: "หักค่าลดหย่อนส่วนตัว 60,000.00"},
+		{Step: StepAllowance, Amount: money.Baht(44000), En: "Less donation allowance: claimed 200,000.00, allowed 44,000.00 (capped at 44,000.00)", Th: "หักค่าลดหย่อน donation: ขอหัก 200,000.00 หักได้ 44,000.00 (เพดาน 44,000.00)"},
+		{Step: StepNetIncome, Amount: money.Baht(396000), En: "Net income 396,000.00", Th: "เงินได้สุทธิ 396,000.00"},
+		{Step: StepLevel, Amount: money.Baht(0), En: "Level 0-150,000: 150,000.00 at 0% = 0.00", Th: "ขั้น 0-150,000: 150,000.00 อัตรา 0% = 0.00"},
+		{Step: StepLevel, Amount: money.Baht(24600), En: "Level 150,001-500,000: 246,000.00 at 10% = 24,600.00", Th: "ขั้น 150,001-500,000: 246,000.00 อัตรา 10% = 24,600.00"},
+		{Step: StepWht, Amount: money.Baht(40000), En: "Less withholding tax 40,000.00", Th: "หักภาษีหัก ณ ที่จ่าย 40,000.00"},
+		{Step: StepTaxRefund, Amount: money.Baht(15400), En: "Tax refund 15,400.00", Th: "ภาษีที่ได้รับคืน 15,400.00"},
+	}
+
+	eng := New(setting)
+	r, err := eng.Calculate(in)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got := eng.Explain(in, r)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v but got %v", want, got)
+	}
+}
+
+func TestFormatAmount(t *testing.T) {
+	tt := []struct {
+		amount money.Money
+		want   string
+	}{
+		{amount: money.Baht(0), want: "0.00"},
+		{amount: money.Baht(0.5), want: "0.50"},
+		{amount: money.Baht(1234567.05), want: "1,234,567.05"},
+	}
+
+	for _, tCase := range tt {
+		got := formatAmount(tCase.amount)
+		if got != tCase.want {
+			t.Errorf("expected %v but got %v", tCase.want, got)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package engine
+
+import (
+	"errors"
+
+	"github.com/thosaphol/assessment-tax/pkg/money"
+)
+
+const (
+	FilingSeparate = "separate"
+	FilingJoint    = "joint"
+)
+
+// FilingResult compares a married couple filing separately with filing
+// jointly. SeparateTax and JointTax are the net liability of each option,
+// negative for a refund.
+type FilingResult struct {
+	Taxpayer    Result
+	Spouse      Result
+	Joint       Result
+	SeparateTax money.Money
+	JointTax    money.Money
+	Recommended string
+}
+
+// CompareFiling calculates both options and recommends the one with the
+// lower liability, separate filing on a tie. A lump sum is taxed separately
+// from either option, so JointTax adds those of both.
+func (e *Engine) CompareFiling(taxpayer, spouse Input) (FilingResult, error) {
+	if ResolveTaxYear(taxpayer.TaxYear) != ResolveTaxYear(spouse.TaxYear) {
+		return FilingResult{}, errors.New("TaxYear of taxpayer and spouse must be the same.")
+	}
+	if ResolvePeriod(taxpayer.Period) != ResolvePeriod(spouse.Period) {
+		return FilingResult{}, errors.New("Period of taxpayer and spouse must be the same.")
+	}
+
+	var fr FilingResult
+	var err error
+	fr.Taxpayer, err = e.Calculate(taxpayer)
+	if err != nil {
+		return FilingResult{}, err
+	}
+	fr.Spouse, err = e.Calculate(spouse)
+	if err != nil {
+		return FilingResult{}, err
+	}
+	fr.Joint, err = e.calculateJoint(taxpayer, spouse, fr.Taxpayer, fr.Spouse)
+	if err != nil {
+		return FilingResult{}, err
+	}
+
+	fr.SeparateTax = liability(fr.Taxpayer).Add(liability(fr.Spouse))
+	fr.JointTax = money.Sum(liability(fr.Joint), lumpSumLiability(fr.Taxpayer), lumpSumLiability(fr.Spouse))
+	fr.Recommended = FilingSeparate
+	if fr.JointTax.LessThan(fr.SeparateTax) {
+		fr.Recommended = FilingJoint
+	}
+	return fr, nil
+}
+
+// calculateJoint files the income of both as one return, with the personal
+// deduction of each and the allowances each is allowed in the separate
+// results, as the caps of an allowance apply to each person.
+func (e *Engine) calculateJoint(taxpayer, spouse Input, tr, sr Result) (Result, error) {
+	s := e.setting
+	s.Personal = s.Personal.Add(s.Personal)
+	in := joinInputs(taxpayer, spouse)
+	in.allowed = joinAllowed(tr.Allowances, sr.Allowances)
+	return New(s).Calculate(in)
+}
+
+// joinInputs merges the income and household of both. Children should be
+// listed by one of them only. Dividends are included when their owner
+// includes them. Lump sums are left out, being taxed by their owner.
+func joinInputs(a, b Input) Input {
+	dividends := append(includedDividends(a), includedDividends(b)...)
+	return Input{
+		TaxYear:            a.TaxYear,
+		Period:             a.Period,
+		TotalIncome:        a.TotalIncome.Add(b.TotalIncome),
+		Incomes:            append(append([]Income(nil), a.Incomes...), b.Incomes...),
+		Wht:                a.Wht.Add(b.Wht),
+		PrepaidHalfYearTax: a.PrepaidHalfYearTax.Add(b.PrepaidHalfYearTax),
+		Dividends:          dividends,
+		IncludeDividends:   len(dividends) > 0,
+		FilingDate:         a.FilingDate,
+		PaymentDate:        a.PaymentDate,
+		Household: Household{
+			Children:           append(append([]Child(nil), a.Household.Children...), b.Household.Children...),
+			Parents:            a.Household.Parents + b.Household.Parents,
+			DisabledDependents: a.Household.DisabledDependents + b.Household.DisabledDependents,
+		},
+	}
+}
+
+// joinAllowed sums the allowed amounts of both by type, leaving out the
+// household deductions the joint return deducts again.
+func joinAllowed(a, b []AllowanceResult) []AllowanceResult {
+	var joined []AllowanceResult
+	index := map[string]int{}
+	for _, r := range append(append([]AllowanceResult(nil), a...), b...) {
+		if _, ok := allowanceRules[r.AllowanceType]; !ok {
+			continue
+		}
+		i, ok := index[r.AllowanceType]
+		if !ok {
+			i = len(joined)
+			index[r.AllowanceType] = i
+			joined = append(joined, AllowanceResult{AllowanceType: r.AllowanceType})
+		}
+		joined[i].Claimed = joined[i].Claimed.Add(r.Claimed)
+		joined[i].Allowed = joined[i].Allowed.Add(r.Allowed)
+		if r.Cap != nil {
+			groupCap := *r.Cap
+			if joined[i].Cap != nil {
+				groupCap = groupCap.Add(*joined[i].Cap)
+			}
+			joined[i].Cap = &groupCap
+		}
+	}
+	return joined
+}
+
+func includedDividends(in Input) []Dividend {
+	if !in.IncludeDividends {
+		return nil
+	}
+	return in.Dividends
+}
+
+func liability(r Result) money.Money {
+	return r.Tax.Sub(r.TaxRefund)
+}
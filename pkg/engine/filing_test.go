@@ -0,0 +1,76 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/thosaphol/assessment-tax/pkg/money"
+)
+
+func TestCompareFiling(t *testing.T) {
+	tt := []struct {
+		name            string
+		taxpayer        Input
+		spouse          Input
+		wantSeparate    money.Money
+		wantJoint       money.Money
+		wantRecommended string
+	}{
+		{
+			name:            "spouse without income should recommend joint",
+			taxpayer:        Input{TotalIncome: money.Baht(1000000)},
+			wantSeparate:    money.Baht(101000),
+			wantJoint:       money.Baht(92000),
+			wantRecommended: FilingJoint,
+		},
+		{
+			name:            "both with the same income should recommend separate",
+			taxpayer:        Input{TotalIncome: money.Baht(1000000)},
+			spouse:          Input{TotalIncome: money.Baht(1000000)},
+			wantSeparate:    money.Baht(202000),
+			wantJoint:       money.Baht(286000),
+			wantRecommended: FilingSeparate,
+		},
+		{
+			name: "allowances should be capped for each spouse in the joint return",
+			taxpayer: Input{TotalIncome: money.Baht(1000000), Allowances: []Allowance{
+				{AllowanceType: "life-insurance", Amount: money.Baht(100000)},
+				{AllowanceType: "k-receipt", Amount: money.Baht(50000)},
+			}},
+			spouse: Input{TotalIncome: money.Baht(1000000), Allowances: []Allowance{
+				{AllowanceType: "life-insurance", Amount: money.Baht(100000)},
+				{AllowanceType: "k-receipt", Amount: money.Baht(50000)},
+			}},
+			wantSeparate:    money.Baht(157000),
+			wantJoint:       money.Baht(226000),
+			wantRecommended: FilingSeparate,
+		},
+		{
+			name:            "refund should count as negative liability",
+			taxpayer:        Input{TotalIncome: money.Baht(560000), Wht: money.Baht(40000)},
+			spouse:          Input{TotalIncome: money.Baht(100000)},
+			wantSeparate:    money.Baht(-5000),
+			wantJoint:       money.Baht(1000),
+			wantRecommended: FilingSeparate,
+		},
+	}
+
+	for _, tCase := range tt {
+		t.Run(tCase.name, func(t *testing.T) {
+			got, err := New(setting).CompareFiling(tCase.taxpayer, tCase.spouse)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got.SeparateTax != tCase.wantSeparate || got.JointTax != tCase.wantJoint || got.Recommended != tCase.wantRecommended {
+				t.Errorf("expected %v %v %v but got %v %v %v", tCase.wantSeparate, tCase.wantJoint, tCase.wantRecommended, got.SeparateTax, got.JointTax, got.Recommended)
+			}
+		})
+	}
+}
+
+func TestCompareFilingTaxYear(t *testing.T) {
+	_, err := New(setting).CompareFiling(Input{TaxYear: 2566}, Input{TaxYear: 2567})
+	want := "TaxYear of taxpayer and spouse must be the same."
+	if err == nil || err.Error() != want {
+		t.Errorf("expected error %q but got %v", want, err)
+	}
+}
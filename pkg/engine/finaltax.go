@@ -0,0 +1,116 @@
+package engine
+
+import (
+	"github.com/thosaphol/assessment-tax/pkg/money"
+)
+
+const (
+	ElectionFinalTax = "final-tax"
+	ElectionAnnual   = "annual-return"
+)
+
+// finalTaxCategories may be taxed at source as final tax, e.g. interest and
+// dividends of 40(4) and some property sales of 40(8).
+var finalTaxCategories = map[string]bool{
+	"40(4)": true,
+	"40(8)": true,
+}
+
+// FinalTaxItem is income taxed at source as final tax and left out of the
+// return, with the tax withheld.
+type FinalTaxItem struct {
+	Category string
+	Amount   money.Money
+	Wht      money.Money
+}
+
+// ElectionResult compares electing final tax on every income that can be
+// taxed at source with including all of it in the return. The totals are
+// the tax borne on each side, withholding included.
+type ElectionResult struct {
+	FinalTax      Result
+	Annual        Result
+	FinalTaxTotal money.Money
+	AnnualTotal   money.Money
+	Recommended   string
+}
+
+// CompareElections calculates both elections and recommends the one with
+// the lower total, final tax on a tie.
+func (e *Engine) CompareElections(in Input) (ElectionResult, error) {
+	var er ElectionResult
+	var err error
+	er.FinalTax, err = e.Calculate(electAll(in, true))
+	if err != nil {
+		return ElectionResult{}, err
+	}
+	er.Annual, err = e.Calculate(electAll(in, false))
+	if err != nil {
+		return ElectionResult{}, err
+	}
+
+	er.FinalTaxTotal = taxBorne(er.FinalTax)
+	er.AnnualTotal = taxBorne(er.Annual)
+	er.Recommended = ElectionFinalTax
+	if er.AnnualTotal.LessThan(er.FinalTaxTotal) {
+		er.Recommended = ElectionAnnual
+	}
+	return er, nil
+}
+
+// taxBorne is the liability of the return with the tax withheld from the
+// incomes and dividends, whether credited or final, added back.
+func taxBorne(r Result) money.Money {
+	return money.Sum(liability(r), r.IncomeWht, r.DividendWht, r.FinalTax)
+}
+
+// electAll elects final tax, or not, on every income that can be taxed at
+// source, dividends included.
+func electAll(in Input, elect bool) Input {
+	incomes := append([]Income(nil), in.Incomes...)
+	for i := range incomes {
+		if finalTaxCategories[incomes[i].Category] {
+			incomes[i].ElectFinalTax = elect
+		}
+	}
+	in.Incomes = incomes
+	in.IncludeDividends = !elect
+	return in
+}
+
+// withFinalTax leaves the incomes elected as final tax, and the dividends
+// not included, out of in and returns them.
+func withFinalTax(in Input) (Input, []FinalTaxItem) {
+	var items []FinalTaxItem
+	var incomes []Income
+	for _, inc := range in.Incomes {
+		if inc.ElectFinalTax {
+			items = append(items, FinalTaxItem{Category: inc.Category, Amount: inc.Amount, Wht: inc.Wht})
+			continue
+		}
+		incomes = append(incomes, inc)
+	}
+	if !in.IncludeDividends {
+		for _, d := range in.Dividends {
+			items = append(items, FinalTaxItem{Category: "40(4)", Amount: d.Amount, Wht: d.Wht})
+		}
+	}
+	in.Incomes = incomes
+	return in, items
+}
+
+func sumFinalTax(items []FinalTaxItem) money.Money {
+	total := money.Zero
+	for _, item := range items {
+		total = total.Add(item.Wht)
+	}
+	return total
+}
+
+func sumIncomeWht(incomes []Income) money.Money {
+	total := money.Zero
+	for _, inc := range incomes {
+		total = total.Add(inc.Wht)
+	}
+	return total
+}
@@ -0,0 +1,134 @@
+package engine
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/thosaphol/assessment-tax/pkg/money"
+)
+
+func TestCalculateFinalTax(t *testing.T) {
+	interest := Income{Category: "40(4)", Amount: money.Baht(100000), Wht: money.Baht(15000)}
+	elected := interest
+	elected.ElectFinalTax = true
+	tt := []struct {
+		name          string
+		in            Input
+		wantTax       money.Money
+		wantIncomeWht money.Money
+		wantItems     []FinalTaxItem
+		wantFinalTax  money.Money
+	}{
+		{
+			name:          "income not elected should be taxed with its withholding credited",
+			in:            Input{TotalIncome: money.Baht(500000), Incomes: []Income{interest}},
+			wantTax:       money.Baht(26000),
+			wantIncomeWht: money.Baht(15000),
+		},
+		{
+			name:         "income elected as final tax should be left out of the brackets",
+			in:           Input{TotalIncome: money.Baht(500000), Incomes: []Income{elected}},
+			wantTax:      money.Baht(29000),
+			wantItems:    []FinalTaxItem{{Category: "40(4)", Amount: money.Baht(100000), Wht: money.Baht(15000)}},
+			wantFinalTax: money.Baht(15000),
+		},
+		{
+			name: "dividend not included should be summarized as final tax",
+			in: Input{
+				TotalIncome: money.Baht(500000),
+				Dividends:   []Dividend{{Amount: money.Baht(100000), CorporateRate: 20, Wht: money.Baht(10000)}},
+			},
+			wantTax:      money.Baht(29000),
+			wantItems:    []FinalTaxItem{{Category: "40(4)", Amount: money.Baht(100000), Wht: money.Baht(10000)}},
+			wantFinalTax: money.Baht(10000),
+		},
+	}
+
+	for _, tCase := range tt {
+		t.Run(tCase.name, func(t *testing.T) {
+			r, err := New(setting).Calculate(tCase.in)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if r.Tax != tCase.wantTax || r.IncomeWht != tCase.wantIncomeWht || r.FinalTax != tCase.wantFinalTax {
+				t.Errorf("expected %v %v %v but got %v %v %v", tCase.wantTax, tCase.wantIncomeWht, tCase.wantFinalTax, r.Tax, r.IncomeWht, r.FinalTax)
+			}
+			if !reflect.DeepEqual(r.FinalTaxItems, tCase.wantItems) {
+				t.Errorf("expected %v but got %v", tCase.wantItems, r.FinalTaxItems)
+			}
+		})
+	}
+}
+
+func TestCompareElections(t *testing.T) {
+	interest := Income{Category: "40(4)", Amount: money.Baht(100000), Wht: money.Baht(15000)}
+	tt := []struct {
+		name              string
+		in                Input
+		wantFinalTaxTotal money.Money
+		wantAnnualTotal   money.Money
+		wantRecommended   string
+	}{
+		{
+			name:              "income below the 15% bracket should be cheaper in the return",
+			in:                Input{TotalIncome: money.Baht(500000), Incomes: []Income{interest}},
+			wantFinalTaxTotal: money.Baht(44000),
+			wantAnnualTotal:   money.Baht(41000),
+			wantRecommended:   ElectionAnnual,
+		},
+		{
+			name:              "income in the 35% bracket should be cheaper as final tax",
+			in:                Input{TotalIncome: money.Baht(6000000), Incomes: []Income{interest}},
+			wantFinalTaxTotal: money.Baht(1704000),
+			wantAnnualTotal:   money.Baht(1724000),
+			wantRecommended:   ElectionFinalTax,
+		},
+		{
+			name:              "income in the 20% bracket should be cheaper as final tax",
+			in:                Input{TotalIncome: money.Baht(1500000), Incomes: []Income{interest}},
+			wantFinalTaxTotal: money.Baht(213000),
+			wantAnnualTotal:   money.Baht(218000),
+			wantRecommended:   ElectionFinalTax,
+		},
+	}
+
+	for _, tCase := range tt {
+		t.Run(tCase.name, func(t *testing.T) {
+			er, err := New(setting).CompareElections(tCase.in)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if er.FinalTaxTotal != tCase.wantFinalTaxTotal || er.AnnualTotal != tCase.wantAnnualTotal || er.Recommended != tCase.wantRecommended {
+				t.Errorf("expected %v %v %v but got %v %v %v", tCase.wantFinalTaxTotal, tCase.wantAnnualTotal, tCase.wantRecommended, er.FinalTaxTotal, er.AnnualTotal, er.Recommended)
+			}
+		})
+	}
+}
+
+func TestValidateFinalTax(t *testing.T) {
+	tt := []struct {
+		name    string
+		incomes []Income
+		want    string
+	}{
+		{
+			name:    "election on salary should be rejected",
+			incomes: []Income{{Category: "40(1)", Amount: money.Baht(100000), ElectFinalTax: true}},
+			want:    "ElectFinalTax is allowed for 40(4) and 40(8) only.",
+		},
+		{
+			name:    "wht over the income should be rejected",
+			incomes: []Income{{Category: "40(4)", Amount: money.Baht(100000), Wht: money.Baht(100001)}},
+			want:    "Wht of income must be in the range 0 to Amount.",
+		},
+	}
+
+	for _, tCase := range tt {
+		t.Run(tCase.name, func(t *testing.T) {
+			err := Validate(Input{Incomes: tCase.incomes})
+			if err == nil || err.Error() != tCase.want {
+				t.Errorf("expected error %q but got %v", tCase.want, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package engine
+
+import (
+	"errors"
+
+	"github.com/thosaphol/assessment-tax/pkg/money"
+)
+
+// GrossUp solves for the smallest income of category, 40(1) when empty, of
+// which the income after tax reaches netIncome, with the other fields of in
+// as given. Income after tax only grows with the income, so the satang
+// amount is found by bisection.
+func (e *Engine) GrossUp(netIncome money.Money, category string, in Input) (Result, error) {
+	if netIncome.IsNegative() {
+		return Result{}, errors.New("NetIncome must have a starting value of 0.")
+	}
+	if category == "" {
+		category = "40(1)"
+	}
+	in.TotalIncome = money.Zero
+	in.Wht = money.Zero
+	in.PrepaidHalfYearTax = money.Zero
+
+	afterTax := func(income money.Money) (Result, bool, error) {
+		in.Incomes = []Income{{Category: category, Amount: income}}
+		r, err := e.Calculate(in)
+		if err != nil {
+			return Result{}, false, err
+		}
+		return r, !income.Sub(r.Tax).LessThan(netIncome), nil
+	}
+
+	lo, hi := int64(-1), netIncome.Satang()
+	for {
+		_, ok, err := afterTax(money.FromSatang(hi))
+		if err != nil {
+			return Result{}, err
+		}
+		if ok {
+			break
+		}
+		if hi > money.Unlimited.Satang()/4 {
+			return Result{}, errors.New("NetIncome is too large.")
+		}
+		lo, hi = hi, hi*2+1
+	}
+	for hi-lo > 1 {
+		mid := lo + (hi-lo)/2
+		_, ok, err := afterTax(money.FromSatang(mid))
+		if err != nil {
+			return Result{}, err
+		}
+		if ok {
+			hi = mid
+		} else {
+			lo = mid
+		}
+	}
+
+	r, _, err := afterTax(money.FromSatang(hi))
+	return r, err
+}
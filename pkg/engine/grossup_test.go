@@ -0,0 +1,75 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/thosaphol/assessment-tax/pkg/money"
+)
+
+func TestGrossUp(t *testing.T) {
+	tt := []struct {
+		name      string
+		netIncome money.Money
+		category  string
+		in        Input
+		want      money.Money
+		wantTax   money.Money
+	}{
+		{
+			name:      "income below tax threshold should be the net income",
+			netIncome: money.Baht(200000),
+			want:      money.Baht(200000),
+		},
+		{
+			name:      "salary 300,000 should be tax free after the 40(1) expense",
+			netIncome: money.Baht(300000),
+			want:      money.Baht(300000),
+		},
+		{
+			name:      "net 481,000 should need salary 500,000",
+			netIncome: money.Baht(481000),
+			want:      money.Baht(500000),
+			wantTax:   money.Baht(19000),
+		},
+		{
+			name:      "net 1,722,000 should need salary 2,000,000 in the 20% bracket",
+			netIncome: money.Baht(1722000),
+			want:      money.Baht(2000000),
+			wantTax:   money.Baht(278000),
+		},
+		{
+			name:      "allowances should lower the income needed",
+			netIncome: money.Baht(486000),
+			in:        Input{Allowances: []Allowance{{AllowanceType: "k-receipt", Amount: money.Baht(50000)}}},
+			want:      money.Baht(500000),
+			wantTax:   money.Baht(14000),
+		},
+		{
+			name:      "income of a category without expense should need more",
+			netIncome: money.Baht(471000),
+			category:  "40(4)",
+			want:      money.Baht(500000),
+			wantTax:   money.Baht(29000),
+		},
+	}
+
+	for _, tCase := range tt {
+		t.Run(tCase.name, func(t *testing.T) {
+			got, err := New(setting).GrossUp(tCase.netIncome, tCase.category, tCase.in)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got.TotalIncome != tCase.want || got.Tax != tCase.wantTax {
+				t.Errorf("expected income %v tax %v but got income %v tax %v", tCase.want, tCase.wantTax, got.TotalIncome, got.Tax)
+			}
+		})
+	}
+}
+
+func TestGrossUpNegative(t *testing.T) {
+	_, err := New(setting).GrossUp(money.Baht(-1), "", Input{})
+	want := "NetIncome must have a starting value of 0."
+	if err == nil || err.Error() != want {
+		t.Errorf("expected error %q but got %v", want, err)
+	}
+}
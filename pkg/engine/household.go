@@ -0,0 +1,78 @@
+package engine
+
+import (
+	"errors"
+
+	"github.com/thosaphol/assessment-tax/pkg/money"
+)
+
+// childBonusYear is the first birth year of which the second and later
+// children get the bonus child allowance.
+const childBonusYear = 2561
+
+// Household is the family of the taxpayer. Spouse is claimed only for a
+// spouse without income. Children are in order of birth with BirthYear in
+// the Buddhist era. Parents counts the parents, aged 60 or over with income
+// of at most 30,000, in the care of the taxpayer.
+type Household struct {
+	Spouse             bool
+	Children           []Child
+	Parents            int
+	DisabledDependents int
+}
+
+type Child struct {
+	BirthYear int
+}
+
+// HouseholdDeduction holds the admin configured amounts per person.
+type HouseholdDeduction struct {
+	Spouse     money.Money
+	Child      money.Money
+	ChildBonus money.Money
+	Parent     money.Money
+	Disability money.Money
+}
+
+func validateHousehold(h Household) error {
+	if h.Parents < 0 || h.DisabledDependents < 0 {
+		return errors.New("Household must have a starting value of 0.")
+	}
+	return nil
+}
+
+// calculateHousehold reports the household allowances in the same form as
+// the other allowances.
+func calculateHousehold(h Household, d HouseholdDeduction) (money.Money, []AllowanceResult) {
+	var results []AllowanceResult
+	add := func(alwType string, amount money.Money) {
+		results = append(results, AllowanceResult{AllowanceType: alwType, Claimed: amount, Allowed: amount})
+	}
+
+	if h.Spouse {
+		add("spouse", d.Spouse)
+	}
+	if len(h.Children) > 0 {
+		child := money.Zero
+		for i, c := range h.Children {
+			if i > 0 && c.BirthYear >= childBonusYear {
+				child = child.Add(d.ChildBonus)
+			} else {
+				child = child.Add(d.Child)
+			}
+		}
+		add("child", child)
+	}
+	if h.Parents > 0 {
+		add("parent", d.Parent.MulRatio(int64(h.Parents), 1))
+	}
+	if h.DisabledDependents > 0 {
+		add("disability", d.Disability.MulRatio(int64(h.DisabledDependents), 1))
+	}
+
+	total := money.Zero
+	for _, r := range results {
+		total = total.Add(r.Allowed)
+	}
+	return total, results
+}
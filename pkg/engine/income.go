@@ -0,0 +1,119 @@
+package engine
+
+import (
+	"errors"
+
+	"github.com/thosaphol/assessment-tax/pkg/money"
+)
+
+// Income is assessable income of one category under section 40 of the
+// Revenue Code. ActualExpense replaces the flat expense rate for 40(5)-40(8)
+// when greater than 0. ForeignTax is the tax paid abroad on the income. Wht
+// is the tax withheld at source, final tax with ElectFinalTax and otherwise
+// credited like the WHT.
+type Income struct {
+	Category      string
+	Amount        money.Money
+	ActualExpense money.Money
+	ForeignTax    money.Money
+	Wht           money.Money
+	ElectFinalTax bool
+}
+
+type IncomeResult struct {
+	Category string
+	Amount   money.Money
+	Expense  money.Money
+	Net      money.Money
+}
+
+type expenseRule struct {
+	rate   int
+	cap    money.Money
+	group  string
+	actual bool
+}
+
+// expenseRules are the statutory expense deductions. Categories in the same
+// group share one cap, e.g. 40(1) and 40(2) together deduct at most 100,000.
+var expenseRules = map[string]expenseRule{
+	"40(1)": {rate: 50, cap: money.Baht(100000), group: "40(1)-40(2)"},
+	"40(2)": {rate: 50, cap: money.Baht(100000), group: "40(1)-40(2)"},
+	"40(3)": {rate: 50, cap: money.Baht(100000), group: "40(3)"},
+	"40(4)": {rate: 0},
+	"40(5)": {rate: 30, actual: true},
+	"40(6)": {rate: 30, actual: true},
+	"40(7)": {rate: 60, actual: true},
+	"40(8)": {rate: 60, actual: true},
+}
+
+func IsIncomeCategory(category string) bool {
+	_, ok := expenseRules[category]
+	return ok
+}
+
+func validateIncomes(incomes []Income) error {
+	for _, inc := range incomes {
+		rule, ok := expenseRules[inc.Category]
+		if !ok {
+			return errors.New("Income category must be 40(1) to 40(8).")
+		}
+		if inc.Amount.IsNegative() {
+			return errors.New("Amount income must have a starting value of 0.")
+		}
+		if inc.ActualExpense.IsNegative() {
+			return errors.New("ActualExpense must have a starting value of 0.")
+		}
+		if !rule.actual && !inc.ActualExpense.IsZero() {
+			return errors.New("ActualExpense is allowed for 40(5) to 40(8) only.")
+		}
+		if inc.Wht.IsNegative() || inc.Wht.GreaterThan(inc.Amount) {
+			return errors.New("Wht of income must be in the range 0 to Amount.")
+		}
+		if inc.ElectFinalTax && !finalTaxCategories[inc.Category] {
+			return errors.New("ElectFinalTax is allowed for 40(4) and 40(8) only.")
+		}
+	}
+	return nil
+}
+
+func calculateIncomes(incomes []Income) []IncomeResult {
+	var results []IncomeResult
+	used := map[string]money.Money{}
+	for _, inc := range incomes {
+		rule := expenseRules[inc.Category]
+
+		expense := inc.Amount.Percent(rule.rate)
+		if rule.actual && !inc.ActualExpense.IsZero() {
+			expense = money.Min(inc.ActualExpense, inc.Amount)
+		}
+		if !rule.cap.IsZero() {
+			expense = money.Min(expense, rule.cap.Sub(used[rule.group]))
+			used[rule.group] = used[rule.group].Add(expense)
+		}
+
+		results = append(results, IncomeResult{
+			Category: inc.Category,
+			Amount:   inc.Amount,
+			Expense:  expense,
+			Net:      inc.Amount.Sub(expense),
+		})
+	}
+	return results
+}
+
+func sumIncomes(incomes []Income) money.Money {
+	total := money.Zero
+	for _, inc := range incomes {
+		total = total.Add(inc.Amount)
+	}
+	return total
+}
+
+func sumNetIncomes(incomes []IncomeResult) money.Money {
+	total := money.Zero
+	for _, inc := range incomes {
+		total = total.Add(inc.Net)
+	}
+	return total
+}
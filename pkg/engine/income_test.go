@@ -0,0 +1,127 @@
+package engine
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/thosaphol/assessment-tax/pkg/money"
+)
+
+func TestCalculateIncomes(t *testing.T) {
+	tt := []struct {
+		name    string
+		incomes []Income
+		want    []IncomeResult
+	}{
+		{
+			name:    "salary 40(1) deducts 50% of expense",
+			incomes: []Income{{Category: "40(1)", Amount: money.Baht(150000)}},
+			want: []IncomeResult{
+				{Category: "40(1)", Amount: money.Baht(150000), Expense: money.Baht(75000), Net: money.Baht(75000)},
+			},
+		},
+		{
+			name: "40(1) and 40(2) share expense cap 100,000",
+			incomes: []Income{
+				{Category: "40(1)", Amount: money.Baht(180000)},
+				{Category: "40(2)", Amount: money.Baht(100000)},
+			},
+			want: []IncomeResult{
+				{Category: "40(1)", Amount: money.Baht(180000), Expense: money.Baht(90000), Net: money.Baht(90000)},
+				{Category: "40(2)", Amount: money.Baht(100000), Expense: money.Baht(10000), Net: money.Baht(90000)},
+			},
+		},
+		{
+			name:    "dividend 40(4) has no expense deduction",
+			incomes: []Income{{Category: "40(4)", Amount: money.Baht(50000)}},
+			want: []IncomeResult{
+				{Category: "40(4)", Amount: money.Baht(50000), Expense: money.Baht(0), Net: money.Baht(50000)},
+			},
+		},
+		{
+			name: "rental 40(5) deducts flat 30% or actual expense",
+			incomes: []Income{
+				{Category: "40(5)", Amount: money.Baht(200000)},
+				{Category: "40(5)", Amount: money.Baht(100000), ActualExpense: money.Baht(45000)},
+			},
+			want: []IncomeResult{
+				{Category: "40(5)", Amount: money.Baht(200000), Expense: money.Baht(60000), Net: money.Baht(140000)},
+				{Category: "40(5)", Amount: money.Baht(100000), Expense: money.Baht(45000), Net: money.Baht(55000)},
+			},
+		},
+		{
+			name:    "business 40(8) deducts flat 60%",
+			incomes: []Income{{Category: "40(8)", Amount: money.Baht(1000000)}},
+			want: []IncomeResult{
+				{Category: "40(8)", Amount: money.Baht(1000000), Expense: money.Baht(600000), Net: money.Baht(400000)},
+			},
+		},
+	}
+
+	for _, tCase := range tt {
+		t.Run(tCase.name, func(t *testing.T) {
+			got := calculateIncomes(tCase.incomes)
+			if !reflect.DeepEqual(got, tCase.want) {
+				t.Errorf("expected %v but got %v", tCase.want, got)
+			}
+		})
+	}
+}
+
+func TestCalculateWithIncomes(t *testing.T) {
+	in := Input{
+		Incomes: []Income{
+			{Category: "40(1)", Amount: money.Baht(600000)},
+			{Category: "40(8)", Amount: money.Baht(100000)},
+		},
+		Wht: money.Baht(10000),
+	}
+
+	got, err := New(setting).Calculate(in)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// 600,000 - 100,000 + 100,000 - 60,000 - 60,000 = 480,000
+	if got.TotalIncome != money.Baht(700000) || got.NetIncome != money.Baht(480000) || got.Tax != money.Baht(23000) {
+		t.Errorf("expected total 700000 net 480000 tax 23000 but got %v %v %v", got.TotalIncome, got.NetIncome, got.Tax)
+	}
+}
+
+func TestValidateIncomes(t *testing.T) {
+	tt := []struct {
+		name    string
+		in      Input
+		wantErr string
+	}{
+		{
+			name:    "given unknown category should return error",
+			in:      Input{Incomes: []Income{{Category: "40(9)"}}},
+			wantErr: "Income category must be 40(1) to 40(8).",
+		},
+		{
+			name:    "given negative income should return error",
+			in:      Input{Incomes: []Income{{Category: "40(1)", Amount: money.Baht(-1)}}},
+			wantErr: "Amount income must have a starting value of 0.",
+		},
+		{
+			name:    "given actual expense for salary should return error",
+			in:      Input{Incomes: []Income{{Category: "40(1)", Amount: money.Baht(100), ActualExpense: money.Baht(10)}}},
+			wantErr: "ActualExpense is allowed for 40(5) to 40(8) only.",
+		},
+		{
+			name:    "given wht greater than income of all categories should return error",
+			in:      Input{Incomes: []Income{{Category: "40(1)", Amount: money.Baht(100)}}, Wht: money.Baht(101)},
+			wantErr: "Wht must be in the range 0 to TotalIncome.",
+		},
+	}
+
+	for _, tCase := range tt {
+		t.Run(tCase.name, func(t *testing.T) {
+			err := Validate(tCase.in)
+			if err == nil || err.Error() != tCase.wantErr {
+				t.Errorf("expected error %q but got %v", tCase.wantErr, err)
+			}
+		})
+	}
+}
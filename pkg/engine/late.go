@@ -0,0 +1,93 @@
+package engine
+
+import (
+	"errors"
+	"time"
+
+	"github.com/thosaphol/assessment-tax/pkg/money"
+)
+
+// buddhistEraOffset converts a tax year in the Buddhist era to the Gregorian
+// calendar.
+const buddhistEraOffset = 543
+
+var (
+	// surchargeRate is the surcharge per month or part of a month, in 0.1%.
+	surchargeRate = int64(15)
+	// lateFineDays is the number of days late after which the higher fine
+	// applies.
+	lateFineDays = 7
+	lateFine     = money.Baht(200)
+	lateFineOver = money.Baht(1000)
+)
+
+// dueDates are the due dates extended by the Revenue Department, by tax year
+// and period.
+var dueDates = map[int]map[string]time.Time{
+	2562: {PeriodAnnual: date(2020, time.August, 31)},
+	2563: {PeriodHalfYear: date(2020, time.December, 30)},
+}
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// DueDate is the last day to file and pay: 31 March of the next year for the
+// annual return and 30 September of the tax year for the half-year return,
+// unless extended.
+func DueDate(taxYear int, period string) time.Time {
+	year, period := ResolveTaxYear(taxYear), ResolvePeriod(period)
+	if d, ok := dueDates[year][period]; ok {
+		return d
+	}
+	if period == PeriodHalfYear {
+		return date(year-buddhistEraOffset, time.September, 30)
+	}
+	return date(year-buddhistEraOffset+1, time.March, 31)
+}
+
+func validateDates(in Input) error {
+	if in.FilingDate.IsZero() {
+		if !in.PaymentDate.IsZero() {
+			return errors.New("FilingDate is required with PaymentDate.")
+		}
+		return nil
+	}
+	if !in.PaymentDate.IsZero() && in.PaymentDate.Before(in.FilingDate) {
+		return errors.New("PaymentDate must not be before FilingDate.")
+	}
+	return nil
+}
+
+// calculateLate returns the surcharge of 1.5% of the tax per month or part of
+// a month paid after the due date, at most the tax, and the fine of filing
+// late, which is owed on a late return even without tax.
+func calculateLate(tax money.Money, due, filing, payment time.Time) (money.Money, money.Money) {
+	if payment.IsZero() {
+		payment = filing
+	}
+
+	surcharge := money.Min(tax.MulRatio(surchargeRate*int64(monthsLate(due, payment)), 1000), tax)
+
+	penalty := money.Zero
+	if filing.After(due) {
+		penalty = lateFine
+		if filing.After(due.AddDate(0, 0, lateFineDays)) {
+			penalty = lateFineOver
+		}
+	}
+	return surcharge, penalty
+}
+
+// monthsLate counts the months, a part of a month as one, from the due date
+// to the day.
+func monthsLate(due, day time.Time) int {
+	if !day.After(due) {
+		return 0
+	}
+	months := (day.Year()-due.Year())*12 + int(day.Month()-due.Month())
+	if day.Day() > due.Day() {
+		months++
+	}
+	return months
+}
@@ -0,0 +1,108 @@
+package engine
+
+import (
+	"testing"
+	"time"
+
+	"github.com/thosaphol/assessment-tax/pkg/money"
+)
+
+func TestDueDate(t *testing.T) {
+	tt := []struct {
+		name   string
+		year   int
+		period string
+		want   time.Time
+	}{
+		{name: "annual return of 2567 is due 31 March 2025", year: 2567, want: date(2025, time.March, 31)},
+		{name: "half-year return of 2567 is due 30 September 2024", year: 2567, period: PeriodHalfYear, want: date(2024, time.September, 30)},
+		{name: "annual return of 2562 was extended to 31 August 2020", year: 2562, period: PeriodAnnual, want: date(2020, time.August, 31)},
+	}
+
+	for _, tCase := range tt {
+		t.Run(tCase.name, func(t *testing.T) {
+			got := DueDate(tCase.year, tCase.period)
+			if !got.Equal(tCase.want) {
+				t.Errorf("expected %v but got %v", tCase.want, got)
+			}
+		})
+	}
+}
+
+func TestCalculateLate(t *testing.T) {
+	due := date(2025, time.March, 31)
+	tt := []struct {
+		name          string
+		tax           money.Money
+		filing        time.Time
+		payment       time.Time
+		wantSurcharge money.Money
+		wantPenalty   money.Money
+	}{
+		{
+			name:   "filing on the due date owes nothing",
+			tax:    money.Baht(29000),
+			filing: due,
+		},
+		{
+			name:          "filing 5 days late owes 1 month surcharge and fine 200",
+			tax:           money.Baht(29000),
+			filing:        date(2025, time.April, 5),
+			wantSurcharge: money.Baht(435),
+			wantPenalty:   money.Baht(200),
+		},
+		{
+			name:          "paying in June owes 3 months surcharge",
+			tax:           money.Baht(29000),
+			filing:        date(2025, time.April, 10),
+			payment:       date(2025, time.June, 15),
+			wantSurcharge: money.Baht(1305),
+			wantPenalty:   money.Baht(1000),
+		},
+		{
+			name:          "surcharge is capped at the tax",
+			tax:           money.Baht(29000),
+			filing:        date(2031, time.April, 1),
+			wantSurcharge: money.Baht(29000),
+			wantPenalty:   money.Baht(1000),
+		},
+		{
+			name:        "no tax filed late should still be fined",
+			tax:         money.Baht(0),
+			filing:      date(2026, time.January, 1),
+			wantPenalty: money.Baht(1000),
+		},
+		{
+			name:   "no tax filed on time owes nothing",
+			tax:    money.Baht(0),
+			filing: date(2025, time.March, 31),
+		},
+	}
+
+	for _, tCase := range tt {
+		t.Run(tCase.name, func(t *testing.T) {
+			surcharge, penalty := calculateLate(tCase.tax, due, tCase.filing, tCase.payment)
+			if surcharge != tCase.wantSurcharge || penalty != tCase.wantPenalty {
+				t.Errorf("expected surcharge %v penalty %v but got surcharge %v penalty %v", tCase.wantSurcharge, tCase.wantPenalty, surcharge, penalty)
+			}
+		})
+	}
+}
+
+func TestCalculateLateFiling(t *testing.T) {
+	in := Input{TotalIncome: money.Baht(500000), FilingDate: date(2025, time.April, 5)}
+
+	got, err := New(setting).Calculate(in)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Tax != money.Baht(29000) || got.Surcharge != money.Baht(435) || got.Penalty != money.Baht(200) || got.TotalPayable != money.Baht(29635) {
+		t.Errorf("expected tax 29000.00 surcharge 435.00 penalty 200.00 payable 29635.00 but got tax %v surcharge %v penalty %v payable %v", got.Tax, got.Surcharge, got.Penalty, got.TotalPayable)
+	}
+
+	_, err = New(setting).Calculate(Input{FilingDate: date(2025, time.April, 5), PaymentDate: date(2025, time.April, 1)})
+	want := "PaymentDate must not be before FilingDate."
+	if err == nil || err.Error() != want {
+		t.Errorf("expected error %q but got %v", want, err)
+	}
+}
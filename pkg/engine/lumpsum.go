@@ -0,0 +1,84 @@
+package engine
+
+import (
+	"errors"
+
+	"github.com/thosaphol/assessment-tax/pkg/money"
+)
+
+const (
+	// lumpSumDeductionPerYear is deducted for each year of service before
+	// the half deduction.
+	lumpSumDeductionPerYear = 7000
+	// minLumpSumYears is the service needed to tax the lump sum separately.
+	minLumpSumYears = 5
+)
+
+// LumpSum is paid once on retirement or termination, taxed separately from
+// the other income when the service is at least 5 years. Wht is the tax
+// withheld from it, credited like the WHT.
+type LumpSum struct {
+	Amount         money.Money
+	YearsOfService int
+	Wht            money.Money
+}
+
+// LumpSumResult breaks down the separate tax of the lump sum, with the
+// brackets applied to NetIncome without any other deduction.
+type LumpSumResult struct {
+	Amount           money.Money
+	YearsOfService   int
+	ServiceDeduction money.Money
+	HalfDeduction    money.Money
+	NetIncome        money.Money
+	Tax              money.Money
+	Wht              money.Money
+	TaxLevels        []TaxLevel
+}
+
+func validateLumpSum(ls *LumpSum) error {
+	if ls == nil {
+		return nil
+	}
+	if ls.Amount.IsNegative() {
+		return errors.New("Amount lump sum must have a starting value of 0.")
+	}
+	if ls.YearsOfService < minLumpSumYears {
+		return errors.New("YearsOfService must be at least 5 to tax the lump sum separately.")
+	}
+	if ls.Wht.IsNegative() || ls.Wht.GreaterThan(ls.Amount) {
+		return errors.New("Wht of lump sum must be in the range 0 to Amount.")
+	}
+	return nil
+}
+
+// calculateLumpSum deducts 7,000 per year of service, up to the amount, then
+// half of the rest, and taxes the remainder on the brackets.
+func calculateLumpSum(ls *LumpSum, tConsts []TaxConst) *LumpSumResult {
+	if ls == nil {
+		return nil
+	}
+	service := money.Min(money.Baht(float64(lumpSumDeductionPerYear*ls.YearsOfService)), ls.Amount)
+	half := ls.Amount.Sub(service).MulRatio(1, 2)
+	net := ls.Amount.Sub(service).Sub(half)
+	tax, levels := calculateTaxLevels(net, tConsts)
+	return &LumpSumResult{
+		Amount:           ls.Amount,
+		YearsOfService:   ls.YearsOfService,
+		ServiceDeduction: service,
+		HalfDeduction:    half,
+		NetIncome:        net,
+		Tax:              tax,
+		Wht:              ls.Wht,
+		TaxLevels:        levels,
+	}
+}
+
+// lumpSumLiability is the tax of the lump sum less its withholding, 0
+// without a lump sum.
+func lumpSumLiability(r Result) money.Money {
+	if r.LumpSum == nil {
+		return money.Zero
+	}
+	return r.LumpSum.Tax.Sub(r.LumpSum.Wht)
+}
@@ -0,0 +1,105 @@
+package engine
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/thosaphol/assessment-tax/pkg/money"
+)
+
+func TestCalculateLumpSum(t *testing.T) {
+	tt := []struct {
+		name        string
+		in          Input
+		wantTax     money.Money
+		wantLumpSum *LumpSumResult
+	}{
+		{
+			name:    "lump sum should be taxed separately and added to the tax",
+			in:      Input{TotalIncome: money.Baht(500000), LumpSum: &LumpSum{Amount: money.Baht(1000000), YearsOfService: 10, Wht: money.Baht(50000)}},
+			wantTax: money.Baht(10500),
+			wantLumpSum: &LumpSumResult{
+				Amount:           money.Baht(1000000),
+				YearsOfService:   10,
+				ServiceDeduction: money.Baht(70000),
+				HalfDeduction:    money.Baht(465000),
+				NetIncome:        money.Baht(465000),
+				Tax:              money.Baht(31500),
+				Wht:              money.Baht(50000),
+			},
+		},
+		{
+			name:    "service deduction should not exceed the lump sum",
+			in:      Input{TotalIncome: money.Baht(500000), LumpSum: &LumpSum{Amount: money.Baht(50000), YearsOfService: 10}},
+			wantTax: money.Baht(29000),
+			wantLumpSum: &LumpSumResult{
+				Amount:           money.Baht(50000),
+				YearsOfService:   10,
+				ServiceDeduction: money.Baht(50000),
+			},
+		},
+		{
+			name:    "no lump sum should have no breakdown",
+			in:      Input{TotalIncome: money.Baht(500000)},
+			wantTax: money.Baht(29000),
+		},
+	}
+
+	for _, tCase := range tt {
+		t.Run(tCase.name, func(t *testing.T) {
+			r, err := New(setting).Calculate(tCase.in)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if r.Tax != tCase.wantTax {
+				t.Errorf("expected tax %v but got %v", tCase.wantTax, r.Tax)
+			}
+			if r.LumpSum != nil {
+				r.LumpSum.TaxLevels = nil
+			}
+			if !reflect.DeepEqual(r.LumpSum, tCase.wantLumpSum) {
+				t.Errorf("expected %v but got %v", tCase.wantLumpSum, r.LumpSum)
+			}
+		})
+	}
+}
+
+func TestCompareFilingWithLumpSum(t *testing.T) {
+	taxpayer := Input{TotalIncome: money.Baht(500000), LumpSum: &LumpSum{Amount: money.Baht(1000000), YearsOfService: 10}}
+
+	fr, err := New(setting).CompareFiling(taxpayer, Input{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fr.SeparateTax != money.Baht(60500) || fr.JointTax != money.Baht(54500) {
+		t.Errorf("expected %v %v but got %v %v", money.Baht(60500), money.Baht(54500), fr.SeparateTax, fr.JointTax)
+	}
+}
+
+func TestValidateLumpSum(t *testing.T) {
+	tt := []struct {
+		name    string
+		lumpSum *LumpSum
+		want    string
+	}{
+		{
+			name:    "service under 5 years should be rejected",
+			lumpSum: &LumpSum{Amount: money.Baht(100000), YearsOfService: 4},
+			want:    "YearsOfService must be at least 5 to tax the lump sum separately.",
+		},
+		{
+			name:    "wht over the lump sum should be rejected",
+			lumpSum: &LumpSum{Amount: money.Baht(100000), YearsOfService: 5, Wht: money.Baht(100001)},
+			want:    "Wht of lump sum must be in the range 0 to Amount.",
+		},
+	}
+
+	for _, tCase := range tt {
+		t.Run(tCase.name, func(t *testing.T) {
+			err := Validate(Input{LumpSum: tCase.lumpSum})
+			if err == nil || err.Error() != tCase.want {
+				t.Errorf("expected error %q but got %v", tCase.want, err)
+			}
+		})
+	}
+}
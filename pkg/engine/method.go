@@ -0,0 +1,40 @@
+package engine
+
+import "github.com/thosaphol/assessment-tax/pkg/money"
+
+const (
+	MethodProgressive = "progressive"
+	MethodGrossIncome = "gross-income"
+)
+
+var (
+	// grossIncomeThreshold is the income other than 40(1) from which the
+	// gross income method has to be compared with the progressive method.
+	grossIncomeThreshold = money.Baht(120000)
+	// grossIncomeExemption exempts a gross income tax up to this amount.
+	grossIncomeExemption = money.Baht(5000)
+)
+
+// calculateGrossIncomeTax returns 0.5% of income other than 40(1) and whether
+// the method applies. Uncategorised TotalIncome is not counted.
+func calculateGrossIncomeTax(incomes []Income) (money.Money, bool) {
+	gross := money.Zero
+	for _, inc := range incomes {
+		if inc.Category != "40(1)" {
+			gross = gross.Add(inc.Amount)
+		}
+	}
+	if gross.LessThan(grossIncomeThreshold) {
+		return money.Zero, false
+	}
+	return gross.MulRatio(5, 1000), true
+}
+
+// chooseTaxMethod returns the higher of the progressive tax and the gross
+// income tax, with the method chosen.
+func chooseTaxMethod(progressive, grossIncome money.Money, applicable bool) (money.Money, string) {
+	if !applicable || !grossIncome.GreaterThan(grossIncomeExemption) || !grossIncome.GreaterThan(progressive) {
+		return progressive, MethodProgressive
+	}
+	return grossIncome, MethodGrossIncome
+}
@@ -0,0 +1,70 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/thosaphol/assessment-tax/pkg/money"
+)
+
+func TestCalculateTaxMethod(t *testing.T) {
+	tt := []struct {
+		name        string
+		incomes     []Income
+		wantApplied bool
+		wantGross   money.Money
+		wantMethod  string
+		wantTax     money.Money
+	}{
+		{
+			name:       "salary only does not compare gross income method",
+			incomes:    []Income{{Category: "40(1)", Amount: money.Baht(5000000)}},
+			wantMethod: MethodProgressive,
+			wantTax:    money.Baht(1304000),
+		},
+		{
+			name:       "income other than 40(1) less than 120,000 does not compare gross income method",
+			incomes:    []Income{{Category: "40(8)", Amount: money.Baht(119999)}},
+			wantMethod: MethodProgressive,
+			wantTax:    money.Baht(0),
+		},
+		{
+			name:        "progressive tax is chosen when higher than gross income tax",
+			incomes:     []Income{{Category: "40(8)", Amount: money.Baht(2000000)}},
+			wantApplied: true,
+			wantGross:   money.Baht(10000),
+			wantMethod:  MethodProgressive,
+			wantTax:     money.Baht(71000),
+		},
+		{
+			name:        "gross income tax up to 5,000 is exempted",
+			incomes:     []Income{{Category: "40(2)", Amount: money.Baht(1000000)}},
+			wantApplied: true,
+			wantGross:   money.Baht(5000),
+			wantMethod:  MethodProgressive,
+			wantTax:     money.Baht(86000),
+		},
+		{
+			name:        "gross income tax is chosen when higher than progressive tax",
+			incomes:     []Income{{Category: "40(8)", Amount: money.Baht(3000000), ActualExpense: money.Baht(2900000)}},
+			wantApplied: true,
+			wantGross:   money.Baht(15000),
+			wantMethod:  MethodGrossIncome,
+			wantTax:     money.Baht(15000),
+		},
+	}
+
+	for _, tCase := range tt {
+		t.Run(tCase.name, func(t *testing.T) {
+			got, err := New(setting).Calculate(Input{Incomes: tCase.incomes})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got.GrossIncomeApplied != tCase.wantApplied || got.GrossIncomeTax != tCase.wantGross {
+				t.Errorf("expected gross income tax %v %v but got %v %v", tCase.wantApplied, tCase.wantGross, got.GrossIncomeApplied, got.GrossIncomeTax)
+			}
+			if got.TaxMethod != tCase.wantMethod || got.Tax != tCase.wantTax {
+				t.Errorf("expected %v %v but got %v %v", tCase.wantMethod, tCase.wantTax, got.TaxMethod, got.Tax)
+			}
+		})
+	}
+}
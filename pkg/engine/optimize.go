@@ -0,0 +1,191 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/thosaphol/assessment-tax/pkg/money"
+)
+
+// DefaultInstruments are the tax-saving allowance types the budget is
+// allocated to when none are given.
+var DefaultInstruments = []string{
+	"rmf",
+	"ssf",
+	"thai-esg",
+	"pension-insurance",
+	"life-insurance",
+	"health-insurance",
+	"donation-double",
+	"donation",
+}
+
+// Allocation is the amount recommended for an allowance type.
+type Allocation struct {
+	AllowanceType string
+	Amount        money.Money
+}
+
+// OptimizeResult has the result before and after the allocations. Budget
+// left in Unallocated saves no more tax.
+type OptimizeResult struct {
+	Before      Result
+	After       Result
+	Allocations []Allocation
+	Invested    money.Money
+	Unallocated money.Money
+	TaxSaving   money.Money
+}
+
+// Optimize allocates budget on top of the allowances of in across the
+// instruments, one step at a time to the instrument that saves the most tax
+// per baht. The caps are applied by calculating the tax, so every rule and
+// group of the allowances is respected.
+func (e *Engine) Optimize(in Input, budget money.Money, instruments []string) (OptimizeResult, error) {
+	if budget.IsNegative() {
+		return OptimizeResult{}, errors.New("Budget must have a starting value of 0.")
+	}
+	if len(instruments) == 0 {
+		instruments = DefaultInstruments
+	}
+	for _, t := range instruments {
+		if _, ok := allowanceRules[t]; !ok {
+			return OptimizeResult{}, fmt.Errorf("AllowanceType '%s' is not supported.", t)
+		}
+	}
+
+	before, err := e.Calculate(in)
+	if err != nil {
+		return OptimizeResult{}, err
+	}
+
+	amounts := map[string]money.Money{}
+	left := budget
+	var after Result
+	for round := 0; ; round++ {
+		left, err = e.allocate(in, instruments, amounts, left, budget)
+		if err != nil {
+			return OptimizeResult{}, err
+		}
+		after, err = e.Calculate(withAllowances(in, instruments, amounts, "", money.Zero))
+		if err != nil {
+			return OptimizeResult{}, err
+		}
+		// later allocations can lower the cap of an earlier one, e.g. of
+		// donations, so what is no longer allowed is allocated again
+		trimmed := trimAllocations(amounts, before, after)
+		if trimmed.IsZero() || round == optimizeRounds {
+			break
+		}
+		left = left.Add(trimmed)
+	}
+
+	or := OptimizeResult{Before: before, After: after, Unallocated: left}
+	for _, t := range instruments {
+		if amount, ok := amounts[t]; ok {
+			or.Allocations = append(or.Allocations, Allocation{AllowanceType: t, Amount: amount})
+			or.Invested = or.Invested.Add(amount)
+		}
+	}
+	or.TaxSaving = liability(before).Sub(liability(or.After))
+	return or, nil
+}
+
+const (
+	optimizeSteps  = 100
+	optimizeRounds = 10
+)
+
+// allocate spends left in steps of a hundredth of budget on the instrument
+// saving the most tax per baht until no step saves more, and returns the
+// budget left.
+func (e *Engine) allocate(in Input, instruments []string, amounts map[string]money.Money, left, budget money.Money) (money.Money, error) {
+	step := money.Max(budget.MulRatio(1, optimizeSteps), minOptimizeStep)
+	current, err := e.Calculate(withAllowances(in, instruments, amounts, "", money.Zero))
+	if err != nil {
+		return left, err
+	}
+	for left.GreaterThan(money.Zero) {
+		chunk := money.Min(step, left)
+		best, bestAmount, bestSaving := "", money.Zero, 0.0
+		var bestResult Result
+		for _, t := range instruments {
+			r, err := e.Calculate(withAllowances(in, instruments, amounts, t, chunk))
+			if err != nil {
+				return left, err
+			}
+			amount := allowedIncrease(current, r, t)
+			saving := liability(current).Sub(liability(r))
+			if !amount.GreaterThan(money.Zero) || !saving.GreaterThan(money.Zero) {
+				continue
+			}
+			perBaht := saving.Float64() / amount.Float64()
+			if perBaht > bestSaving {
+				best, bestAmount, bestSaving, bestResult = t, amount, perBaht, r
+			}
+		}
+		if best == "" {
+			break
+		}
+		amounts[best] = amounts[best].Add(bestAmount)
+		current = bestResult
+		left = left.Sub(bestAmount)
+	}
+	return left, nil
+}
+
+// trimAllocations lowers the amounts to what after allows over before and
+// returns the total trimmed.
+func trimAllocations(amounts map[string]money.Money, before, after Result) money.Money {
+	trimmed := money.Zero
+	for t, amount := range amounts {
+		allowed := money.Max(allowedIncrease(before, after, t), money.Zero)
+		if !amount.GreaterThan(allowed) {
+			continue
+		}
+		trimmed = trimmed.Add(amount.Sub(allowed))
+		if allowed.IsZero() {
+			delete(amounts, t)
+		} else {
+			amounts[t] = allowed
+		}
+	}
+	return trimmed
+}
+
+var minOptimizeStep = money.Baht(1000)
+
+// withAllowances returns in with the amounts allocated to the instruments
+// and chunk more of t.
+func withAllowances(in Input, instruments []string, amounts map[string]money.Money, t string, chunk money.Money) Input {
+	alws := append([]Allowance(nil), in.Allowances...)
+	for _, typ := range instruments {
+		if amount, ok := amounts[typ]; ok {
+			alws = append(alws, Allowance{AllowanceType: typ, Amount: amount})
+		}
+	}
+	if t != "" {
+		alws = append(alws, Allowance{AllowanceType: t, Amount: chunk})
+	}
+	in.Allowances = alws
+	return in
+}
+
+// allowedIncrease is how much more of t was claimed and allowed from before
+// to after, in baht paid rather than the counted amount.
+func allowedIncrease(before, after Result, t string) money.Money {
+	increase := allowedOf(after, t).Sub(allowedOf(before, t))
+	if m := allowanceRules[t].Multiplier; m > 1 {
+		increase = increase.MulRatio(1, int64(m))
+	}
+	return increase
+}
+
+func allowedOf(r Result, t string) money.Money {
+	for _, alw := range r.Allowances {
+		if alw.AllowanceType == t {
+			return alw.Allowed
+		}
+	}
+	return money.Zero
+}
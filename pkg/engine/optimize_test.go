@@ -0,0 +1,111 @@
+package engine
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/thosaphol/assessment-tax/pkg/money"
+)
+
+func TestOptimize(t *testing.T) {
+	tt := []struct {
+		name            string
+		in              Input
+		budget          money.Money
+		instruments     []string
+		wantAllocations []Allocation
+		wantUnallocated money.Money
+		wantSaving      money.Money
+	}{
+		{
+			name:            "budget within the cap should go to the first instrument",
+			in:              Input{TotalIncome: money.Baht(1000000)},
+			budget:          money.Baht(100000),
+			instruments:     []string{"rmf", "ssf"},
+			wantAllocations: []Allocation{{AllowanceType: "rmf", Amount: money.Baht(100000)}},
+			wantSaving:      money.Baht(15000),
+		},
+		{
+			name:        "budget over the income rate cap should move to the next instrument",
+			in:          Input{TotalIncome: money.Baht(1000000)},
+			budget:      money.Baht(400000),
+			instruments: []string{"rmf", "ssf"},
+			wantAllocations: []Allocation{
+				{AllowanceType: "rmf", Amount: money.Baht(300000)},
+				{AllowanceType: "ssf", Amount: money.Baht(100000)},
+			},
+			wantSaving: money.Baht(60000),
+		},
+		{
+			name:        "budget over the group caps should be left unallocated",
+			in:          Input{TotalIncome: money.Baht(3000000)},
+			budget:      money.Baht(2000000),
+			instruments: []string{"rmf", "ssf", "life-insurance", "health-insurance"},
+			wantAllocations: []Allocation{
+				{AllowanceType: "rmf", Amount: money.Baht(500000)},
+				{AllowanceType: "life-insurance", Amount: money.Baht(100000)},
+			},
+			wantUnallocated: money.Baht(1400000),
+			wantSaving:      money.Baht(210000),
+		},
+		{
+			name:        "current allowances should be counted in the caps",
+			in:          Input{TotalIncome: money.Baht(1000000), Allowances: []Allowance{{AllowanceType: "provident-fund", Amount: money.Baht(150000)}}},
+			budget:      money.Baht(400000),
+			instruments: []string{"rmf", "ssf"},
+			wantAllocations: []Allocation{
+				{AllowanceType: "rmf", Amount: money.Baht(300000)},
+				{AllowanceType: "ssf", Amount: money.Baht(50000)},
+			},
+			wantUnallocated: money.Baht(50000),
+			wantSaving:      money.Baht(49500),
+		},
+		{
+			name:   "double donation should be lowered to the cap left by the other allocations",
+			in:     Input{TotalIncome: money.Baht(1000000)},
+			budget: money.Baht(400000),
+			wantAllocations: []Allocation{
+				{AllowanceType: "rmf", Amount: money.Baht(300000)},
+				{AllowanceType: "ssf", Amount: money.Baht(71578.94)},
+				{AllowanceType: "donation-double", Amount: money.Baht(28421.06)},
+			},
+			wantSaving: money.Baht(64263.16),
+		},
+	}
+
+	for _, tCase := range tt {
+		t.Run(tCase.name, func(t *testing.T) {
+			got, err := New(setting).Optimize(tCase.in, tCase.budget, tCase.instruments)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got.Allocations, tCase.wantAllocations) {
+				t.Errorf("expected %v but got %v", tCase.wantAllocations, got.Allocations)
+			}
+			if got.Unallocated != tCase.wantUnallocated || got.TaxSaving != tCase.wantSaving {
+				t.Errorf("expected %v %v but got %v %v", tCase.wantUnallocated, tCase.wantSaving, got.Unallocated, got.TaxSaving)
+			}
+		})
+	}
+}
+
+func TestOptimizeValidation(t *testing.T) {
+	tt := []struct {
+		name        string
+		budget      money.Money
+		instruments []string
+		wantErr     string
+	}{
+		{name: "negative budget", budget: money.Baht(-1), wantErr: "Budget must have a starting value of 0."},
+		{name: "unsupported instrument", budget: money.Baht(1000), instruments: []string{"gold"}, wantErr: "AllowanceType 'gold' is not supported."},
+	}
+
+	for _, tCase := range tt {
+		t.Run(tCase.name, func(t *testing.T) {
+			_, err := New(setting).Optimize(Input{TotalIncome: money.Baht(500000)}, tCase.budget, tCase.instruments)
+			if err == nil || err.Error() != tCase.wantErr {
+				t.Errorf("expected error %q but got %v", tCase.wantErr, err)
+			}
+		})
+	}
+}
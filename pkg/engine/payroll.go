@@ -0,0 +1,122 @@
+package engine
+
+import (
+	"errors"
+
+	"github.com/thosaphol/assessment-tax/pkg/money"
+)
+
+const monthsPerYear = 12
+
+// Payroll is the 40(1) pay of one employee for a tax year. Month is the
+// month, 1 to 12, to withhold for. Allowances and Household are those the
+// employee declared to the employer.
+type Payroll struct {
+	TaxYear    int
+	Month      int
+	Salary     money.Money
+	Bonuses    []Bonus
+	Allowances []Allowance
+	Household  Household
+}
+
+type Bonus struct {
+	Month  int
+	Amount money.Money
+}
+
+type PayrollMonth struct {
+	Month  int
+	Salary money.Money
+	Bonus  money.Money
+	Wht    money.Money
+}
+
+// PayrollResult has the WHT of the requested month and the schedule of the
+// year. The schedule sums to AnnualTax unless the WHT of earlier months
+// already exceeds the tax projected later, e.g. after the income drops, as
+// the remaining months withhold 0 rather than refund.
+type PayrollResult struct {
+	TaxYear   int
+	Month     int
+	Wht       money.Money
+	AnnualTax money.Money
+	Schedule  []PayrollMonth
+}
+
+func validatePayroll(p Payroll) error {
+	if p.Month < 1 || p.Month > monthsPerYear {
+		return errors.New("Month must be in the range 1 to 12.")
+	}
+	if p.Salary.IsNegative() {
+		return errors.New("Salary must have a starting value of 0.")
+	}
+	for _, b := range p.Bonuses {
+		if b.Month < 1 || b.Month > monthsPerYear {
+			return errors.New("Month of bonus must be in the range 1 to 12.")
+		}
+		if b.Amount.IsNegative() {
+			return errors.New("Amount bonus must have a starting value of 0.")
+		}
+	}
+	return nil
+}
+
+// CalculatePayroll withholds by the annualize-and-divide method. Each month
+// the salary is projected to the end of the year on top of the pay to date,
+// and the annual tax of it less the WHT to date is spread over the months
+// left. The extra tax a bonus adds to the projection is withheld in full in
+// the month it is paid.
+func (e *Engine) CalculatePayroll(p Payroll) (PayrollResult, error) {
+	if err := validatePayroll(p); err != nil {
+		return PayrollResult{}, err
+	}
+
+	bonuses := make([]money.Money, monthsPerYear+1)
+	for _, b := range p.Bonuses {
+		bonuses[b.Month] = bonuses[b.Month].Add(b.Amount)
+	}
+
+	annualTax := func(income money.Money) (money.Money, error) {
+		r, err := e.Calculate(Input{
+			TaxYear:    p.TaxYear,
+			Incomes:    []Income{{Category: "40(1)", Amount: income}},
+			Allowances: p.Allowances,
+			Household:  p.Household,
+		})
+		return r.Tax, err
+	}
+
+	var err error
+	pr := PayrollResult{TaxYear: ResolveTaxYear(p.TaxYear), Month: p.Month}
+	paid, withheld := money.Zero, money.Zero
+	for m := 1; m <= monthsPerYear; m++ {
+		left := int64(monthsPerYear - m + 1)
+		projected := paid.Add(p.Salary.MulRatio(left, 1))
+		tax, err := annualTax(projected)
+		if err != nil {
+			return PayrollResult{}, err
+		}
+		wht := money.Max(tax.Sub(withheld), money.Zero).MulRatio(1, left)
+
+		if !bonuses[m].IsZero() {
+			withBonus, err := annualTax(projected.Add(bonuses[m]))
+			if err != nil {
+				return PayrollResult{}, err
+			}
+			wht = wht.Add(withBonus.Sub(tax))
+		}
+
+		pr.Schedule = append(pr.Schedule, PayrollMonth{Month: m, Salary: p.Salary, Bonus: bonuses[m], Wht: wht})
+		if m == p.Month {
+			pr.Wht = wht
+		}
+		paid = paid.Add(p.Salary).Add(bonuses[m])
+		withheld = withheld.Add(wht)
+	}
+	pr.AnnualTax, err = annualTax(paid)
+	if err != nil {
+		return PayrollResult{}, err
+	}
+	return pr, nil
+}
@@ -0,0 +1,94 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/thosaphol/assessment-tax/pkg/money"
+)
+
+func TestCalculatePayroll(t *testing.T) {
+	tt := []struct {
+		name          string
+		p             Payroll
+		wantWht       money.Money
+		wantAnnualTax money.Money
+	}{
+		{
+			name:          "salary 50,000 should withhold 2,416.67 in January",
+			p:             Payroll{Month: 1, Salary: money.Baht(50000)},
+			wantWht:       money.Baht(2416.67),
+			wantAnnualTax: money.Baht(29000),
+		},
+		{
+			name:          "salary 50,000 should withhold the remainder in December",
+			p:             Payroll{Month: 12, Salary: money.Baht(50000)},
+			wantWht:       money.Baht(2416.66),
+			wantAnnualTax: money.Baht(29000),
+		},
+		{
+			name:          "bonus 100,000 in June should withhold its extra tax in June",
+			p:             Payroll{Month: 6, Salary: money.Baht(50000), Bonuses: []Bonus{{Month: 6, Amount: money.Baht(100000)}}},
+			wantWht:       money.Baht(14416.66),
+			wantAnnualTax: money.Baht(41000),
+		},
+		{
+			name:          "salary under the tax threshold should withhold nothing",
+			p:             Payroll{Month: 3, Salary: money.Baht(20000)},
+			wantWht:       money.Baht(0),
+			wantAnnualTax: money.Baht(0),
+		},
+	}
+
+	for _, tCase := range tt {
+		t.Run(tCase.name, func(t *testing.T) {
+			got, err := New(setting).CalculatePayroll(tCase.p)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got.Wht != tCase.wantWht || got.AnnualTax != tCase.wantAnnualTax {
+				t.Errorf("expected wht %v annual tax %v but got wht %v annual tax %v", tCase.wantWht, tCase.wantAnnualTax, got.Wht, got.AnnualTax)
+			}
+
+			total := money.Zero
+			for _, m := range got.Schedule {
+				total = total.Add(m.Wht)
+			}
+			if len(got.Schedule) != 12 || total != got.AnnualTax {
+				t.Errorf("expected 12 months withholding %v but got %d months withholding %v", got.AnnualTax, len(got.Schedule), total)
+			}
+		})
+	}
+}
+
+func TestCalculatePayrollValidation(t *testing.T) {
+	tt := []struct {
+		name    string
+		p       Payroll
+		wantErr string
+	}{
+		{
+			name:    "given month 13 should return error",
+			p:       Payroll{Month: 13},
+			wantErr: "Month must be in the range 1 to 12.",
+		},
+		{
+			name:    "given negative salary should return error",
+			p:       Payroll{Month: 1, Salary: money.Baht(-1)},
+			wantErr: "Salary must have a starting value of 0.",
+		},
+		{
+			name:    "given bonus in month 0 should return error",
+			p:       Payroll{Month: 1, Bonuses: []Bonus{{Amount: money.Baht(1)}}},
+			wantErr: "Month of bonus must be in the range 1 to 12.",
+		},
+	}
+
+	for _, tCase := range tt {
+		t.Run(tCase.name, func(t *testing.T) {
+			_, err := New(setting).CalculatePayroll(tCase.p)
+			if err == nil || err.Error() != tCase.wantErr {
+				t.Errorf("expected error %q but got %v", tCase.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package engine
+
+import (
+	"errors"
+
+	"github.com/thosaphol/assessment-tax/pkg/money"
+)
+
+const (
+	PeriodAnnual   = "annual"
+	PeriodHalfYear = "half-year"
+)
+
+// halfYearCategories are the income categories filed in the half-year
+// return (ภ.ง.ด.94).
+var halfYearCategories = map[string]bool{
+	"40(5)": true,
+	"40(6)": true,
+	"40(7)": true,
+	"40(8)": true,
+}
+
+func ResolvePeriod(period string) string {
+	if period == "" {
+		return PeriodAnnual
+	}
+	return period
+}
+
+func validatePeriod(in Input) error {
+	period := ResolvePeriod(in.Period)
+	if period != PeriodAnnual && period != PeriodHalfYear {
+		return errors.New("Period must be annual or half-year.")
+	}
+	if in.PrepaidHalfYearTax.IsNegative() {
+		return errors.New("PrepaidHalfYearTax must have a starting value of 0.")
+	}
+	if period != PeriodHalfYear {
+		return nil
+	}
+	if !in.PrepaidHalfYearTax.IsZero() {
+		return errors.New("PrepaidHalfYearTax is only credited in the annual period.")
+	}
+	if !in.TotalIncome.IsZero() {
+		return errors.New("TotalIncome is only counted in the annual period.")
+	}
+	if len(in.Dividends) > 0 {
+		return errors.New("Dividends are only counted in the annual period.")
+	}
+	if in.LumpSum != nil {
+		return errors.New("LumpSum is only taxed in the annual period.")
+	}
+	return nil
+}
+
+// halfYearInput keeps only the income of the half-year categories.
+func halfYearInput(in Input) Input {
+	var incomes []Income
+	for _, inc := range in.Incomes {
+		if halfYearCategories[inc.Category] {
+			incomes = append(incomes, inc)
+		}
+	}
+	in.Incomes = incomes
+	return in
+}
+
+// halfYearSetting halves the personal and household deductions, and through
+// halfYear the caps of the allowances claimable in the half-year return.
+func halfYearSetting(s Setting) Setting {
+	half := func(m money.Money) money.Money { return m.MulRatio(1, 2) }
+	s.Personal = half(s.Personal)
+	s.Household = HouseholdDeduction{
+		Spouse:     half(s.Household.Spouse),
+		Child:      half(s.Household.Child),
+		ChildBonus: half(s.Household.ChildBonus),
+		Parent:     half(s.Household.Parent),
+		Disability: half(s.Household.Disability),
+	}
+	s.halfYear = true
+	return s
+}
@@ -0,0 +1,75 @@
+package engine
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/thosaphol/assessment-tax/pkg/money"
+)
+
+// Scenario is a named variation of a base input.
+type Scenario struct {
+	Name  string
+	Input Input
+}
+
+// ScenarioResult compares a scenario with the base. TaxDelta is the change of
+// the net liability, negative for a saving. Contribution is the change of the
+// claimed allowances, and SavingPerBaht the tax saved for each baht of it.
+type ScenarioResult struct {
+	Name          string
+	Result        Result
+	TaxDelta      money.Money
+	Contribution  money.Money
+	SavingPerBaht float64
+}
+
+// CompareScenarios calculates the base and every scenario against it.
+func (e *Engine) CompareScenarios(base Input, scenarios []Scenario) (Result, []ScenarioResult, error) {
+	names := map[string]bool{}
+	for _, sc := range scenarios {
+		if names[sc.Name] {
+			return Result{}, nil, fmt.Errorf("Scenario name '%s' is duplicated.", sc.Name)
+		}
+		names[sc.Name] = true
+	}
+
+	br, err := e.Calculate(base)
+	if err != nil {
+		return Result{}, nil, err
+	}
+
+	var results []ScenarioResult
+	for _, sc := range scenarios {
+		r, err := e.Calculate(sc.Input)
+		if err != nil {
+			return Result{}, nil, fmt.Errorf("Scenario '%s': %w", sc.Name, err)
+		}
+		sr := ScenarioResult{
+			Name:         sc.Name,
+			Result:       r,
+			TaxDelta:     liability(r).Sub(liability(br)),
+			Contribution: sumClaimed(sc.Input.Allowances).Sub(sumClaimed(base.Allowances)),
+		}
+		sr.SavingPerBaht = savingPerBaht(sr.TaxDelta, sr.Contribution)
+		results = append(results, sr)
+	}
+	return br, results, nil
+}
+
+func sumClaimed(alws []Allowance) money.Money {
+	total := money.Zero
+	for _, alw := range alws {
+		total = total.Add(alw.Amount)
+	}
+	return total
+}
+
+// savingPerBaht returns the tax saved per baht contributed to 4 decimal
+// places, 0 without a contribution.
+func savingPerBaht(delta, contribution money.Money) float64 {
+	if contribution.IsZero() {
+		return 0
+	}
+	return math.Round(float64(delta.Neg().Satang())*10000/float64(contribution.Satang())) / 10000
+}
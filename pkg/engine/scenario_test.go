@@ -0,0 +1,60 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/thosaphol/assessment-tax/pkg/money"
+)
+
+func TestCompareScenarios(t *testing.T) {
+	base := Input{TotalIncome: money.Baht(1000000)}
+	tt := []struct {
+		name             string
+		in               Input
+		wantDelta        money.Money
+		wantContribution money.Money
+		wantSaving       float64
+	}{
+		{
+			name:             "rmf of 100,000 should save 15 satang per baht",
+			in:               Input{TotalIncome: money.Baht(1000000), Allowances: []Allowance{{AllowanceType: "rmf", Amount: money.Baht(100000)}}},
+			wantDelta:        money.Baht(-15000),
+			wantContribution: money.Baht(100000),
+			wantSaving:       0.15,
+		},
+		{
+			name:             "donation over the cap should save less per baht",
+			in:               Input{TotalIncome: money.Baht(1000000), Allowances: []Allowance{{AllowanceType: "donation", Amount: money.Baht(200000)}}},
+			wantDelta:        money.Baht(-14100),
+			wantContribution: money.Baht(200000),
+			wantSaving:       0.0705,
+		},
+		{
+			name:             "higher income without contribution should have no saving",
+			in:               Input{TotalIncome: money.Baht(1100000)},
+			wantDelta:        money.Baht(17000),
+			wantContribution: money.Baht(0),
+			wantSaving:       0,
+		},
+	}
+
+	for _, tCase := range tt {
+		t.Run(tCase.name, func(t *testing.T) {
+			_, got, err := New(setting).CompareScenarios(base, []Scenario{{Name: tCase.name, Input: tCase.in}})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got[0].TaxDelta != tCase.wantDelta || got[0].Contribution != tCase.wantContribution || got[0].SavingPerBaht != tCase.wantSaving {
+				t.Errorf("expected %v %v %v but got %v %v %v", tCase.wantDelta, tCase.wantContribution, tCase.wantSaving, got[0].TaxDelta, got[0].Contribution, got[0].SavingPerBaht)
+			}
+		})
+	}
+}
+
+func TestCompareScenariosDuplicatedName(t *testing.T) {
+	_, _, err := New(setting).CompareScenarios(Input{}, []Scenario{{Name: "rmf"}, {Name: "rmf"}})
+	want := "Scenario name 'rmf' is duplicated."
+	if err == nil || err.Error() != want {
+		t.Errorf("expected error %q but got %v", want, err)
+	}
+}
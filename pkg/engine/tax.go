@@ -0,0 +1,106 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/thosaphol/assessment-tax/pkg/money"
+)
+
+const DefaultTaxYear = 2567
+
+// TaxConst is one tax bracket. The top bracket has money.Unlimited as Upper.
+type TaxConst struct {
+	Lower   money.Money
+	Upper   money.Money
+	TaxRate int
+	Level   string
+}
+
+func NewTaxConst(lower, upper money.Money, rate int) TaxConst {
+	return TaxConst{Lower: lower, Upper: upper, TaxRate: rate, Level: levelName(lower, upper)}
+}
+
+// brackets2560 has applied since the 2560 reform and is unchanged through 2568.
+var brackets2560 = []TaxConst{
+	NewTaxConst(money.Baht(0), money.Baht(150000), 0),
+	NewTaxConst(money.Baht(150000), money.Baht(500000), 10),
+	NewTaxConst(money.Baht(500000), money.Baht(1000000), 15),
+	NewTaxConst(money.Baht(1000000), money.Baht(2000000), 20),
+	NewTaxConst(money.Baht(2000000), money.Unlimited, 35),
+}
+
+var taxConsts = map[int][]TaxConst{
+	2565: brackets2560,
+	2566: brackets2560,
+	2567: brackets2560,
+	2568: brackets2560,
+}
+
+func ResolveTaxYear(year int) int {
+	if year == 0 {
+		return DefaultTaxYear
+	}
+	return year
+}
+
+func GetTaxConsts(year int) ([]TaxConst, error) {
+	tConsts, ok := taxConsts[ResolveTaxYear(year)]
+	if !ok {
+		return nil, errors.New("TaxYear is not supported.")
+	}
+	return append([]TaxConst(nil), tConsts...), nil
+}
+
+func ValidateTaxConsts(tConsts []TaxConst) error {
+	if len(tConsts) == 0 {
+		return errors.New("Tax brackets must have at least 1 level.")
+	}
+	if !tConsts[0].Lower.IsZero() {
+		return errors.New("Lower of the first tax bracket must be 0.")
+	}
+	for i, tConst := range tConsts {
+		if !tConst.Upper.GreaterThan(tConst.Lower) {
+			return fmt.Errorf("Upper of tax bracket %d must be greater than its lower.", i+1)
+		}
+		if tConst.TaxRate < 0 || tConst.TaxRate > 100 {
+			return fmt.Errorf("TaxRate of tax bracket %d must be in the range 0 to 100.", i+1)
+		}
+		if i == 0 {
+			continue
+		}
+		prev := tConsts[i-1]
+		if tConst.Lower.LessThan(prev.Upper) {
+			return fmt.Errorf("Tax bracket %d overlaps the previous bracket.", i+1)
+		}
+		if tConst.Lower.GreaterThan(prev.Upper) {
+			return fmt.Errorf("Tax bracket %d is not contiguous with the previous bracket.", i+1)
+		}
+		if tConst.TaxRate <= prev.TaxRate {
+			return fmt.Errorf("TaxRate of tax bracket %d must be greater than the previous bracket.", i+1)
+		}
+	}
+	if !tConsts[len(tConsts)-1].Upper.IsUnlimited() {
+		return errors.New("Upper of the last tax bracket must be unbounded.")
+	}
+	return nil
+}
+
+func levelName(lower, upper money.Money) string {
+	if upper.IsUnlimited() {
+		return fmt.Sprintf("%s ขึ้นไป", formatBaht(lower.Add(money.Baht(1))))
+	}
+	if lower.IsZero() {
+		return fmt.Sprintf("0-%s", formatBaht(upper))
+	}
+	return fmt.Sprintf("%s-%s", formatBaht(lower.Add(money.Baht(1))), formatBaht(upper))
+}
+
+func formatBaht(amount money.Money) string {
+	s := strconv.FormatInt(amount.Satang()/100, 10)
+	for i := len(s) - 3; i > 0; i -= 3 {
+		s = s[:i] + "," + s[i:]
+	}
+	return s
+}
@@ -0,0 +1,206 @@
+package money
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Money is an exact amount of baht stored as satang (1/100 baht).
+// Every operation that can produce a fraction of a satang rounds half-up
+// (away from zero), the Revenue Department practice.
+type Money struct {
+	satang int64
+}
+
+// Unlimited marks an amount without an upper bound, e.g. the top tax bracket.
+var Unlimited = Money{satang: math.MaxInt64}
+
+var Zero = Money{}
+
+func Baht(amount float64) Money {
+	return Money{satang: int64(math.Round(amount * 100))}
+}
+
+func FromSatang(satang int64) Money {
+	return Money{satang: satang}
+}
+
+// Parse reads a decimal string such as "1,234.565" without going through
+// float64, rounding to satang half-up.
+func Parse(s string) (Money, error) {
+	s = strings.ReplaceAll(strings.TrimSpace(s), ",", "")
+	r, ok := new(big.Rat).SetString(s)
+	if !ok || strings.ContainsAny(s, "/eE") {
+		return Zero, fmt.Errorf("invalid amount %q", s)
+	}
+	return fromRat(r.Mul(r, big.NewRat(100, 1)))
+}
+
+func fromRat(r *big.Rat) (Money, error) {
+	num := new(big.Int).Abs(r.Num())
+	q, rem := new(big.Int).QuoRem(num, r.Denom(), new(big.Int))
+	if rem.Mul(rem, big.NewInt(2)).Cmp(r.Denom()) >= 0 {
+		q.Add(q, big.NewInt(1))
+	}
+	if !q.IsInt64() {
+		return Zero, errors.New("amount out of range")
+	}
+	if r.Sign() < 0 {
+		q.Neg(q)
+	}
+	return Money{satang: q.Int64()}, nil
+}
+
+func (m Money) Satang() int64 {
+	return m.satang
+}
+
+func (m Money) Float64() float64 {
+	return float64(m.satang) / 100
+}
+
+func (m Money) IsZero() bool {
+	return m.satang == 0
+}
+
+func (m Money) IsNegative() bool {
+	return m.satang < 0
+}
+
+func (m Money) IsUnlimited() bool {
+	return m == Unlimited
+}
+
+func (m Money) Cmp(o Money) int {
+	switch {
+	case m.satang < o.satang:
+		return -1
+	case m.satang > o.satang:
+		return 1
+	}
+	return 0
+}
+
+func (m Money) GreaterThan(o Money) bool {
+	return m.satang > o.satang
+}
+
+func (m Money) LessThan(o Money) bool {
+	return m.satang < o.satang
+}
+
+func (m Money) Add(o Money) Money {
+	return Money{satang: m.satang + o.satang}
+}
+
+func (m Money) Sub(o Money) Money {
+	return Money{satang: m.satang - o.satang}
+}
+
+func (m Money) Neg() Money {
+	return Money{satang: -m.satang}
+}
+
+// MulRatio returns m * num / den rounded half-up to satang.
+func (m Money) MulRatio(num, den int64) Money {
+	r := new(big.Rat).SetFrac(new(big.Int).Mul(big.NewInt(m.satang), big.NewInt(num)), big.NewInt(den))
+	res, err := fromRat(r)
+	if err != nil {
+		return Unlimited
+	}
+	return res
+}
+
+// Percent returns rate percent of m rounded half-up to satang.
+func (m Money) Percent(rate int) Money {
+	return m.MulRatio(int64(rate), 100)
+}
+
+func Min(a, b Money) Money {
+	if a.LessThan(b) {
+		return a
+	}
+	return b
+}
+
+func Max(a, b Money) Money {
+	if a.GreaterThan(b) {
+		return a
+	}
+	return b
+}
+
+func Sum(ms ...Money) Money {
+	total := Zero
+	for _, m := range ms {
+		total = total.Add(m)
+	}
+	return total
+}
+
+func (m Money) String() string {
+	sign := ""
+	s := m.satang
+	if s < 0 {
+		sign = "-"
+		s = -s
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, s/100, s%100)
+}
+
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+func (m *Money) UnmarshalJSON(data []byte) error {
+	s := string(data)
+	if s == "null" {
+		return nil
+	}
+	if unquoted, err := strconv.Unquote(s); err == nil {
+		s = unquoted
+	}
+	v, err := Parse(s)
+	if err != nil {
+		return err
+	}
+	*m = v
+	return nil
+}
+
+func (m Money) Value() (driver.Value, error) {
+	return m.String(), nil
+}
+
+func (m *Money) Scan(src any) error {
+	switch v := src.(type) {
+	case nil:
+		*m = Zero
+		return nil
+	case []byte:
+		return m.scanString(string(v))
+	case string:
+		return m.scanString(v)
+	case int64:
+		*m = Money{satang: v * 100}
+		return nil
+	case float64:
+		*m = Baht(v)
+		return nil
+	}
+	return fmt.Errorf("cannot scan %T into Money", src)
+}
+
+func (m *Money) scanString(s string) error {
+	v, err := Parse(s)
+	if err != nil {
+		return err
+	}
+	*m = v
+	return nil
+}
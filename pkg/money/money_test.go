@@ -0,0 +1,112 @@
+package money
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tt := []struct {
+		name    string
+		s       string
+		want    Money
+		wantErr bool
+	}{
+		{name: "integer amount", s: "500000", want: FromSatang(50000000)},
+		{name: "amount with satang", s: "0.10", want: FromSatang(10)},
+		{name: "amount with thousand separator", s: "1,234.5", want: FromSatang(123450)},
+		{name: "half satang rounds up", s: "0.005", want: FromSatang(1)},
+		{name: "below half satang rounds down", s: "0.0049", want: FromSatang(0)},
+		{name: "negative half satang rounds away from zero", s: "-0.005", want: FromSatang(-1)},
+		{name: "invalid amount", s: "abc", wantErr: true},
+		{name: "exponent is not accepted", s: "1e3", wantErr: true},
+	}
+
+	for _, tCase := range tt {
+		t.Run(tCase.name, func(t *testing.T) {
+			got, err := Parse(tCase.s)
+			if tCase.wantErr {
+				if err == nil {
+					t.Errorf("expected error but got %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tCase.want {
+				t.Errorf("expected %v but got %v", tCase.want, got)
+			}
+		})
+	}
+}
+
+func TestArithmetic(t *testing.T) {
+	tt := []struct {
+		name string
+		got  Money
+		want Money
+	}{
+		{name: "0.1 + 0.2 is exactly 0.3", got: Baht(0.1).Add(Baht(0.2)), want: Baht(0.3)},
+		{name: "15 percent of 1 baht", got: Baht(1).Percent(15), want: FromSatang(15)},
+		{name: "10 percent of 0.05 rounds half-up", got: Baht(0.05).Percent(10), want: FromSatang(1)},
+		{name: "10 percent of 0.04 rounds down", got: Baht(0.04).Percent(10), want: FromSatang(0)},
+		{name: "0.5 percent of 1,000,001", got: Baht(1000001).MulRatio(5, 1000), want: Baht(5000.01)},
+		{name: "min of two amounts", got: Min(Baht(10), Baht(5)), want: Baht(5)},
+		{name: "sum of amounts", got: Sum(Baht(1), Baht(2.5), Baht(3)), want: Baht(6.5)},
+	}
+
+	for _, tCase := range tt {
+		t.Run(tCase.name, func(t *testing.T) {
+			if tCase.got != tCase.want {
+				t.Errorf("expected %v but got %v", tCase.want, tCase.got)
+			}
+		})
+	}
+}
+
+func TestJSON(t *testing.T) {
+	type body struct {
+		Amount Money `json:"amount"`
+	}
+
+	var got body
+	if err := json.Unmarshal([]byte(`{"amount": 29000.155}`), &got); err != nil {
+		t.Fatalf("unable to unmarshal json: %v", err)
+	}
+	if got.Amount != FromSatang(2900016) {
+		t.Errorf("expected %v but got %v", FromSatang(2900016), got.Amount)
+	}
+
+	b, err := json.Marshal(body{Amount: Baht(-1234.5)})
+	if err != nil {
+		t.Fatalf("unable to marshal json: %v", err)
+	}
+	if string(b) != `{"amount":-1234.50}` {
+		t.Errorf("expected %s but got %s", `{"amount":-1234.50}`, b)
+	}
+}
+
+func TestScan(t *testing.T) {
+	tt := []struct {
+		name string
+		src  any
+		want Money
+	}{
+		{name: "numeric column", src: []byte("60000.00"), want: Baht(60000)},
+		{name: "integer column", src: int64(50000), want: Baht(50000)},
+		{name: "null column", src: nil, want: Zero},
+	}
+
+	for _, tCase := range tt {
+		t.Run(tCase.name, func(t *testing.T) {
+			var got Money
+			if err := got.Scan(tCase.src); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tCase.want {
+				t.Errorf("expected %v but got %v", tCase.want, got)
+			}
+		})
+	}
+}
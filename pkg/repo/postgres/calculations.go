@@ -0,0 +1,51 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/thosaphol/assessment-tax/pkg/repo"
+)
+
+func (p *Postgres) SaveCalculations(cs []repo.Calculation) ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	tx, err := p.Db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var ids []string
+	for _, c := range cs {
+		var id string
+		row := tx.QueryRowContext(ctx, "INSERT INTO calculations(input,setting,output) VALUES($1,$2,$3) RETURNING id;",
+			[]byte(c.Input), []byte(c.Setting), []byte(c.Output))
+		err = row.Scan(&id)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, tx.Commit()
+}
+
+func (p *Postgres) Calculation(id string) (repo.Calculation, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	row := p.Db.QueryRowContext(ctx, "SELECT id,input,setting,output,created_at FROM calculations WHERE id=$1;", id)
+
+	var c repo.Calculation
+	err := row.Scan(&c.ID, &c.Input, &c.Setting, &c.Output, &c.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return repo.Calculation{}, repo.ErrNotFound
+	}
+	if err != nil {
+		return repo.Calculation{}, err
+	}
+	return c, nil
+}
@@ -2,24 +2,31 @@ package postgres
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"time"
+
+	"github.com/thosaphol/assessment-tax/pkg/engine"
+	"github.com/thosaphol/assessment-tax/pkg/money"
+	"github.com/thosaphol/assessment-tax/pkg/repo"
 )
 
 var dbTimeout = time.Second * 3
 
-type Deduction struct {
-	Personal    float64
-	MaxKReceipt float64
-}
-
-func (p *Postgres) SetPersonalDeduction(amount float64) error {
+func (p *Postgres) SetPersonalDeduction(amount money.Money) error {
 	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
 	defer cancel()
 
+	tx, err := p.beginSettings(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
 	var dCount int
 
-	row := p.Db.QueryRowContext(ctx, "SELECT count(*) as count FROM deductions;")
-	err := row.Scan(&dCount)
+	row := tx.QueryRowContext(ctx, "SELECT count(*) as count FROM deductions;")
+	err = row.Scan(&dCount)
 	if err != nil {
 		return err
 	}
@@ -31,7 +38,7 @@ func (p *Postgres) SetPersonalDeduction(amount float64) error {
 		stmt = "UPDATE deductions SET personal=$1;"
 	}
 
-	r, err := p.Db.ExecContext(ctx, stmt, amount)
+	r, err := tx.ExecContext(ctx, stmt, amount)
 	if err != nil {
 		return err
 	}
@@ -39,34 +46,27 @@ func (p *Postgres) SetPersonalDeduction(amount float64) error {
 	if _, err := r.RowsAffected(); err != nil {
 		return err
 	}
-	return nil
+	err = snapshotSettings(ctx, tx)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
-func (p *Postgres) PersonalDeduction() (float64, error) {
+func (p *Postgres) SetKReceiptDeduction(amount money.Money) error {
 	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
 	defer cancel()
 
-	row := p.Db.QueryRowContext(ctx, "SELECT personal,maximum_k_receipt FROM deductions")
-
-	var d Deduction
-	err := row.Scan(&d.Personal,
-		&d.MaxKReceipt)
-
+	tx, err := p.beginSettings(ctx)
 	if err != nil {
-		return 0, err
+		return err
 	}
-
-	return d.Personal, nil
-}
-
-func (p *Postgres) SetKReceiptDeduction(amount float64) error {
-	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
-	defer cancel()
+	defer tx.Rollback()
 
 	var dCount int
 
-	row := p.Db.QueryRowContext(ctx, "SELECT count(*) as count FROM deductions;")
-	err := row.Scan(&dCount)
+	row := tx.QueryRowContext(ctx, "SELECT count(*) as count FROM deductions;")
+	err = row.Scan(&dCount)
 	if err != nil {
 		return err
 	}
@@ -78,7 +78,7 @@ func (p *Postgres) SetKReceiptDeduction(amount float64) error {
 		stmt = "UPDATE deductions SET maximum_k_receipt=$1;"
 	}
 
-	r, err := p.Db.ExecContext(ctx, stmt, amount)
+	r, err := tx.ExecContext(ctx, stmt, amount)
 	if err != nil {
 		return err
 	}
@@ -86,20 +86,108 @@ func (p *Postgres) SetKReceiptDeduction(amount float64) error {
 	if _, err := r.RowsAffected(); err != nil {
 		return err
 	}
-	return nil
+	err = snapshotSettings(ctx, tx)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
 }
-func (p *Postgres) KReceiptDeduction() (float64, error) {
+func (p *Postgres) SetHouseholdDeduction(d engine.HouseholdDeduction) error {
 	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
 	defer cancel()
 
-	row := p.Db.QueryRowContext(ctx, "SELECT maximum_k_receipt FROM deductions")
+	tx, err := p.beginSettings(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var dCount int
+	row := tx.QueryRowContext(ctx, "SELECT count(*) as count FROM deductions;")
+	err = row.Scan(&dCount)
+	if err != nil {
+		return err
+	}
+
+	var stmt string
+	if dCount == 0 {
+		stmt = "INSERT INTO deductions(spouse,child,child_bonus,parent,disability) VALUES($1,$2,$3,$4,$5);"
+	} else {
+		stmt = "UPDATE deductions SET spouse=$1,child=$2,child_bonus=$3,parent=$4,disability=$5;"
+	}
+	r, err := tx.ExecContext(ctx, stmt, d.Spouse, d.Child, d.ChildBonus, d.Parent, d.Disability)
+	if err != nil {
+		return err
+	}
+	if _, err := r.RowsAffected(); err != nil {
+		return err
+	}
+	err = snapshotSettings(ctx, tx)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
 
-	var d float64
-	err := row.Scan(&d)
+// beginSettings begins setting the deductions or the tax brackets, one admin
+// at a time so that each version snapshots the values set with it.
+func (p *Postgres) beginSettings(ctx context.Context) (*sql.Tx, error) {
+	tx, err := p.Db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	_, err = tx.ExecContext(ctx, "LOCK TABLE deductions, tax_brackets IN EXCLUSIVE MODE;")
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	return tx, nil
+}
 
+// snapshotSettings keeps the deductions and the tax brackets set in tx as a
+// new version.
+func snapshotSettings(ctx context.Context, tx *sql.Tx) error {
+	var version int64
+	row := tx.QueryRowContext(ctx, "INSERT INTO deduction_versions(personal,maximum_k_receipt,spouse,child,child_bonus,parent,disability) "+
+		"SELECT personal,maximum_k_receipt,spouse,child,child_bonus,parent,disability FROM deductions RETURNING version;")
+	err := row.Scan(&version)
 	if err != nil {
-		return 0, err
+		return err
 	}
+	_, err = tx.ExecContext(ctx, "INSERT INTO tax_bracket_versions(version,tax_year,lower_bound,upper_bound,tax_rate) "+
+		"SELECT $1,tax_year,lower_bound,upper_bound,tax_rate FROM tax_brackets;", version)
+	return err
+}
 
+// DeductionSnapshot reads the current deductions for version 0 from the
+// deductions table with the latest version, 0 when none is kept yet.
+func (p *Postgres) DeductionSnapshot(version int64) (repo.DeductionSnapshot, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	var row *sql.Row
+	if version == 0 {
+		row = p.Db.QueryRowContext(ctx, "SELECT COALESCE((SELECT max(version) FROM deduction_versions),0),"+
+			"personal,maximum_k_receipt,spouse,child,child_bonus,parent,disability FROM deductions;")
+	} else {
+		row = p.Db.QueryRowContext(ctx, "SELECT version,personal,maximum_k_receipt,spouse,child,child_bonus,parent,disability "+
+			"FROM deduction_versions WHERE version=$1;", version)
+	}
+
+	var d repo.DeductionSnapshot
+	err := row.Scan(&d.Version,
+		&d.Personal,
+		&d.MaxKReceipt,
+		&d.Household.Spouse,
+		&d.Household.Child,
+		&d.Household.ChildBonus,
+		&d.Household.Parent,
+		&d.Household.Disability)
+	if errors.Is(err, sql.ErrNoRows) {
+		return repo.DeductionSnapshot{}, repo.ErrNotFound
+	}
+	if err != nil {
+		return repo.DeductionSnapshot{}, err
+	}
 	return d, nil
 }
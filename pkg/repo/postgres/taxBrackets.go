@@ -0,0 +1,93 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/thosaphol/assessment-tax/pkg/engine"
+	"github.com/thosaphol/assessment-tax/pkg/money"
+)
+
+func (p *Postgres) SetTaxBrackets(year int, tConsts []engine.TaxConst) error {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	tx, err := p.beginSettings(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, "DELETE FROM tax_brackets WHERE tax_year=$1;", year)
+	if err != nil {
+		return err
+	}
+
+	for _, tConst := range tConsts {
+		var upper *money.Money
+		if !tConst.Upper.IsUnlimited() {
+			upper = &tConst.Upper
+		}
+		_, err = tx.ExecContext(ctx, "INSERT INTO tax_brackets(tax_year,lower_bound,upper_bound,tax_rate) VALUES($1,$2,$3,$4);",
+			year, tConst.Lower, upper, tConst.TaxRate)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = snapshotSettings(ctx, tx)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (p *Postgres) TaxBrackets(year int) ([]engine.TaxConst, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	rows, err := p.Db.QueryContext(ctx, "SELECT lower_bound,upper_bound,tax_rate FROM tax_brackets WHERE tax_year=$1 ORDER BY lower_bound;", year)
+	if err != nil {
+		return nil, err
+	}
+	return scanTaxConsts(rows)
+}
+
+// TaxBracketSnapshot reads the brackets of year kept with version, the
+// current ones for version 0.
+func (p *Postgres) TaxBracketSnapshot(version int64, year int) ([]engine.TaxConst, error) {
+	if version == 0 {
+		return p.TaxBrackets(year)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	rows, err := p.Db.QueryContext(ctx, "SELECT lower_bound,upper_bound,tax_rate FROM tax_bracket_versions WHERE version=$1 AND tax_year=$2 ORDER BY lower_bound;", version, year)
+	if err != nil {
+		return nil, err
+	}
+	return scanTaxConsts(rows)
+}
+
+func scanTaxConsts(rows *sql.Rows) ([]engine.TaxConst, error) {
+	defer rows.Close()
+
+	var tConsts []engine.TaxConst
+	for rows.Next() {
+		var lower money.Money
+		var upper *money.Money
+		var rate int
+		err := rows.Scan(&lower, &upper, &rate)
+		if err != nil {
+			return nil, err
+		}
+		u := money.Unlimited
+		if upper != nil {
+			u = *upper
+		}
+		tConsts = append(tConsts, engine.NewTaxConst(lower, u, rate))
+	}
+
+	return tConsts, rows.Err()
+}
@@ -1,8 +1,49 @@
 package repo
 
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/thosaphol/assessment-tax/pkg/engine"
+	"github.com/thosaphol/assessment-tax/pkg/money"
+)
+
+// ErrNotFound is returned when there is no record with the given ID.
+var ErrNotFound = errors.New("record not found")
+
+// Calculation is a saved calculation with its input, the setting it was
+// calculated with and its output, kept as JSON.
+type Calculation struct {
+	ID        string
+	Input     json.RawMessage
+	Setting   json.RawMessage
+	Output    json.RawMessage
+	CreatedAt time.Time
+}
+
+// DeductionSnapshot is the deductions as they were set at Version, which
+// grows each time the admin sets a deduction or the tax brackets.
+type DeductionSnapshot struct {
+	Version     int64
+	Personal    money.Money
+	MaxKReceipt money.Money
+	Household   engine.HouseholdDeduction
+}
+
 type Storer interface {
-	SetPersonalDeduction(amount float64) error
-	PersonalDeduction() (float64, error)
-	SetKReceiptDeduction(amount float64) error
-	KReceiptDeduction() (float64, error)
+	SetPersonalDeduction(amount money.Money) error
+	SetKReceiptDeduction(amount money.Money) error
+	SetHouseholdDeduction(d engine.HouseholdDeduction) error
+	// DeductionSnapshot returns the deductions at version, the current ones
+	// for version 0.
+	DeductionSnapshot(version int64) (DeductionSnapshot, error)
+	SetTaxBrackets(year int, tConsts []engine.TaxConst) error
+	TaxBrackets(year int) ([]engine.TaxConst, error)
+	// TaxBracketSnapshot returns the brackets of year at version, the
+	// current ones for version 0.
+	TaxBracketSnapshot(version int64, year int) ([]engine.TaxConst, error)
+	// SaveCalculations saves all of cs or none and returns their IDs in order.
+	SaveCalculations(cs []Calculation) ([]string, error)
+	Calculation(id string) (Calculation, error)
 }
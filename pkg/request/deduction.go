@@ -3,16 +3,28 @@ package request
 import (
 	"encoding/json"
 	"errors"
+	"reflect"
 
 	"github.com/go-playground/validator/v10"
+	"github.com/thosaphol/assessment-tax/pkg/engine"
+	"github.com/thosaphol/assessment-tax/pkg/money"
 	"github.com/thosaphol/assessment-tax/utils"
 )
 
 type PersonalDeduction struct {
-	Amount float64 `json:"amount" validate:"min=10000,max=100000.0" errormgs:"Invalid amount is required 10,000.0 to 100,000.0"`
+	Amount money.Money `json:"amount" validate:"min=10000,max=100000.0" errormgs:"Invalid amount is required 10,000.0 to 100,000.0"`
 }
 type KReceiptDeduction struct {
-	Amount float64 `json:"amount" validate:"min=0,max=100000.0" errormgs:"Invalid amount is required 0.0 to 100,000.0"`
+	Amount money.Money `json:"amount" validate:"min=0,max=100000.0" errormgs:"Invalid amount is required 0.0 to 100,000.0"`
+}
+
+// HouseholdDeduction is the amount per person of the household allowances.
+type HouseholdDeduction struct {
+	Spouse     money.Money `json:"spouse" validate:"min=0,max=100000.0" errormgs:"Invalid spouse is required 0.0 to 100,000.0"`
+	Child      money.Money `json:"child" validate:"min=0,max=100000.0" errormgs:"Invalid child is required 0.0 to 100,000.0"`
+	ChildBonus money.Money `json:"childBonus" validate:"min=0,max=100000.0" errormgs:"Invalid childBonus is required 0.0 to 100,000.0"`
+	Parent     money.Money `json:"parent" validate:"min=0,max=100000.0" errormgs:"Invalid parent is required 0.0 to 100,000.0"`
+	Disability money.Money `json:"disability" validate:"min=0,max=100000.0" errormgs:"Invalid disability is required 0.0 to 100,000.0"`
 }
 
 func (d *PersonalDeduction) BindFromMap(m map[string]interface{}) error {
@@ -59,11 +71,52 @@ func (k *KReceiptDeduction) BindFromMap(m map[string]interface{}) error {
 
 }
 
+func (h *HouseholdDeduction) BindFromMap(m map[string]interface{}) error {
+	jsonsTag := utils.GetJsonTags(*h)
+	for _, jTag := range jsonsTag {
+		_, ok := m[jTag]
+		if !ok {
+			return errors.New("Json structure invalid")
+		}
+	}
+
+	// Convert the map to JSON
+	jsonData, _ := json.Marshal(m)
+	json.Unmarshal(jsonData, h)
+
+	err := h.validate()
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (h HouseholdDeduction) Deduction() engine.HouseholdDeduction {
+	return engine.HouseholdDeduction{
+		Spouse:     h.Spouse,
+		Child:      h.Child,
+		ChildBonus: h.ChildBonus,
+		Parent:     h.Parent,
+		Disability: h.Disability,
+	}
+}
+
 func (m *PersonalDeduction) validate() error {
-	validate := validator.New(validator.WithRequiredStructEnabled())
-	return utils.ValidateFunc[PersonalDeduction](*m, validate, "errormgs")
+	return utils.ValidateFunc[PersonalDeduction](*m, newValidator(), "errormgs")
 }
 func (k *KReceiptDeduction) validate() error {
+	return utils.ValidateFunc[KReceiptDeduction](*k, newValidator(), "errormgs")
+}
+
+func (h *HouseholdDeduction) validate() error {
+	return utils.ValidateFunc[HouseholdDeduction](*h, newValidator(), "errormgs")
+}
+
+// newValidator lets min/max tags compare money.Money fields in baht.
+func newValidator() *validator.Validate {
 	validate := validator.New(validator.WithRequiredStructEnabled())
-	return utils.ValidateFunc[KReceiptDeduction](*k, validate, "errormgs")
+	validate.RegisterCustomTypeFunc(func(field reflect.Value) interface{} {
+		return field.Interface().(money.Money).Float64()
+	}, money.Money{})
+	return validate
 }
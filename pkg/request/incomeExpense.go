@@ -1,12 +1,190 @@
 package request
 
+import (
+	"github.com/thosaphol/assessment-tax/pkg/money"
+	"github.com/thosaphol/assessment-tax/utils"
+)
+
+// IncomeExpense is an annual return unless Period is "half-year".
+// PrepaidHalfYearTax is the tax paid with the half-year return. Dividends
+// are taxed with a dividend credit only when IncludeDividends. FilingDate
+// and PaymentDate, as YYYY-MM-DD, assess the surcharge and fine of filing
+// late.
 type IncomeExpense struct {
-	TotalIncome float64     `json:"totalIncome"`
-	Wht         float64     `json:"wht"`
-	Allowances  []Allowance `json:"allowances"`
+	TaxYear            int         `json:"taxYear"`
+	Period             string      `json:"period"`
+	TotalIncome        money.Money `json:"totalIncome"`
+	Incomes            []Income    `json:"incomes"`
+	Wht                money.Money `json:"wht"`
+	PrepaidHalfYearTax money.Money `json:"prepaidHalfYearTax"`
+	Dividends          []Dividend  `json:"dividends" validate:"dive"`
+	IncludeDividends   bool        `json:"includeDividends"`
+	LumpSum            *LumpSum    `json:"lumpSum"`
+	Allowances         []Allowance `json:"allowances"`
+	FilingDate         string      `json:"filingDate" validate:"omitempty,datetime=2006-01-02" errormgs:"Invalid filingDate is required YYYY-MM-DD"`
+	PaymentDate        string      `json:"paymentDate" validate:"omitempty,datetime=2006-01-02" errormgs:"Invalid paymentDate is required YYYY-MM-DD"`
+	Household
+}
+
+// HouseholdIncomeExpense is the income of a married couple to compare
+// separate with joint filing.
+type HouseholdIncomeExpense struct {
+	Taxpayer IncomeExpense `json:"taxpayer"`
+	Spouse   IncomeExpense `json:"spouse"`
+}
+
+// GrossUp asks for the income of Category, a 40(1) salary when empty, of
+// which the income after tax is NetIncome, with the given allowances and
+// household.
+type GrossUp struct {
+	TaxYear    int         `json:"taxYear"`
+	NetIncome  money.Money `json:"netIncome" validate:"min=0" errormgs:"Invalid netIncome is required 0.0 or more"`
+	Category   string      `json:"category"`
+	Allowances []Allowance `json:"allowances"`
+	Household
+}
+
+// Payroll is the monthly 40(1) pay of an employee to withhold for Month.
+type Payroll struct {
+	TaxYear    int         `json:"taxYear"`
+	Month      int         `json:"month" validate:"min=1,max=12" errormgs:"Invalid month is required 1 to 12"`
+	Salary     money.Money `json:"salary" validate:"min=0" errormgs:"Invalid salary is required 0.0 or more"`
+	Bonuses    []Bonus     `json:"bonuses" validate:"dive"`
+	Allowances []Allowance `json:"allowances"`
+	Household
+}
+
+// Scenarios evaluates named variations of Base side by side.
+type Scenarios struct {
+	Base      IncomeExpense `json:"base"`
+	Scenarios []Scenario    `json:"scenarios" validate:"min=1,dive" errormgs:"Invalid scenarios is required at least 1"`
+}
+
+// Scenario overrides TotalIncome and Wht of the base when given. Allowances
+// replace the base amount of the same type and add the other types.
+type Scenario struct {
+	Name        string       `json:"name" validate:"required" errormgs:"Invalid name is required"`
+	TotalIncome *money.Money `json:"totalIncome"`
+	Wht         *money.Money `json:"wht"`
+	Allowances  []Allowance  `json:"allowances"`
+}
+
+// Optimize asks how to allocate Budget across the Instruments, the
+// allowance types to invest in, on top of the income and allowances.
+type Optimize struct {
+	IncomeExpense
+	Budget      money.Money `json:"budget" validate:"min=0" errormgs:"Invalid budget is required 0.0 or more"`
+	Instruments []string    `json:"instruments"`
+}
+
+type Bonus struct {
+	Month  int         `json:"month" validate:"min=1,max=12" errormgs:"Invalid month is required 1 to 12"`
+	Amount money.Money `json:"amount" validate:"min=0" errormgs:"Invalid amount is required 0.0 or more"`
+}
+
+// Household is the family of the taxpayer for the household allowances.
+type Household struct {
+	Spouse             *Spouse  `json:"spouse"`
+	Children           []Child  `json:"children" validate:"dive"`
+	Parents            []Parent `json:"parents" validate:"max=4,dive" errormgs:"Invalid parents is required at most 4"`
+	DisabledDependents int      `json:"disabledDependents" validate:"min=0" errormgs:"Invalid disabledDependents is required 0 or more"`
+}
+
+// Spouse is claimed only when HasIncome is false.
+type Spouse struct {
+	HasIncome bool `json:"hasIncome"`
+}
+
+// Child is listed in order of birth with BirthYear in the Buddhist era.
+type Child struct {
+	BirthYear int `json:"birthYear" validate:"min=2400" errormgs:"Invalid birthYear is required in the Buddhist era"`
+}
+
+// Parent is one parent of the taxpayer or the spouse in the care of the
+// taxpayer.
+type Parent struct {
+	Age    int         `json:"age" validate:"min=60" errormgs:"Invalid age is required 60 or more"`
+	Income money.Money `json:"income" validate:"min=0,max=30000" errormgs:"Invalid income is required 0.0 to 30,000.0"`
+}
+
+// Income is assessable income of a category "40(1)" to "40(8)". ForeignTax
+// is the tax paid abroad on it.
+type Income struct {
+	Category      string      `json:"category"`
+	Amount        money.Money `json:"amount"`
+	ActualExpense money.Money `json:"actualExpense"`
+	ForeignTax    money.Money `json:"foreignTax"`
+	Wht           money.Money `json:"wht"`
+	ElectFinalTax bool        `json:"electFinalTax"`
+}
+
+// Dividend is a Thai dividend paid from profit taxed at CorporateRate.
+type Dividend struct {
+	Amount        money.Money `json:"amount" validate:"min=0" errormgs:"Invalid amount is required 0.0 or more"`
+	CorporateRate int         `json:"corporateRate" validate:"min=0,max=30" errormgs:"Invalid corporateRate is required 0 to 30"`
+	Wht           money.Money `json:"wht" validate:"min=0" errormgs:"Invalid wht is required 0.0 or more"`
+}
+
+// LumpSum is paid once on retirement or termination and taxed separately.
+type LumpSum struct {
+	Amount         money.Money `json:"amount" validate:"min=0" errormgs:"Invalid amount is required 0.0 or more"`
+	YearsOfService int         `json:"yearsOfService" validate:"min=0" errormgs:"Invalid yearsOfService is required 0 or more"`
+	Wht            money.Money `json:"wht" validate:"min=0" errormgs:"Invalid wht is required 0.0 or more"`
 }
 
 type Allowance struct {
-	AllowanceType string  `json:"allowanceType"`
-	Amount        float64 `json:"amount"`
+	AllowanceType string      `json:"allowanceType"`
+	Amount        money.Money `json:"amount"`
+}
+
+func (ie *IncomeExpense) Validate() error {
+	return utils.ValidateFunc[IncomeExpense](*ie, newValidator(), "errormgs")
+}
+
+func (hie *HouseholdIncomeExpense) Validate() error {
+	return utils.ValidateFunc[HouseholdIncomeExpense](*hie, newValidator(), "errormgs")
+}
+
+func (g *GrossUp) Validate() error {
+	return utils.ValidateFunc[GrossUp](*g, newValidator(), "errormgs")
+}
+
+func (g GrossUp) IncomeExpense() IncomeExpense {
+	return IncomeExpense{TaxYear: g.TaxYear, Allowances: g.Allowances, Household: g.Household}
+}
+
+func (p *Payroll) Validate() error {
+	return utils.ValidateFunc[Payroll](*p, newValidator(), "errormgs")
+}
+
+func (s *Scenarios) Validate() error {
+	return utils.ValidateFunc[Scenarios](*s, newValidator(), "errormgs")
+}
+
+// Apply returns base with the overrides of the scenario.
+func (s Scenario) Apply(base IncomeExpense) IncomeExpense {
+	ie := base
+	if s.TotalIncome != nil {
+		ie.TotalIncome = *s.TotalIncome
+	}
+	if s.Wht != nil {
+		ie.Wht = *s.Wht
+	}
+
+	overridden := map[string]bool{}
+	for _, alw := range s.Allowances {
+		overridden[alw.AllowanceType] = true
+	}
+	ie.Allowances = nil
+	for _, alw := range base.Allowances {
+		if !overridden[alw.AllowanceType] {
+			ie.Allowances = append(ie.Allowances, alw)
+		}
+	}
+	ie.Allowances = append(ie.Allowances, s.Allowances...)
+	return ie
+}
+
+func (o *Optimize) Validate() error {
+	return utils.ValidateFunc[Optimize](*o, newValidator(), "errormgs")
 }
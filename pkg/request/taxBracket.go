@@ -0,0 +1,29 @@
+package request
+
+import (
+	"github.com/thosaphol/assessment-tax/pkg/engine"
+	"github.com/thosaphol/assessment-tax/pkg/money"
+)
+
+type TaxBrackets struct {
+	TaxBrackets []TaxBracket `json:"taxBrackets"`
+}
+
+// TaxBracket leaves Upper empty for the top, unbounded bracket.
+type TaxBracket struct {
+	Lower   money.Money  `json:"lower"`
+	Upper   *money.Money `json:"upper"`
+	TaxRate int          `json:"taxRate"`
+}
+
+func (t TaxBrackets) TaxConsts() []engine.TaxConst {
+	var tConsts []engine.TaxConst
+	for _, b := range t.TaxBrackets {
+		upper := money.Unlimited
+		if b.Upper != nil {
+			upper = *b.Upper
+		}
+		tConsts = append(tConsts, engine.NewTaxConst(b.Lower, upper, b.TaxRate))
+	}
+	return tConsts
+}
@@ -0,0 +1,28 @@
+package response
+
+import (
+	"encoding/json"
+
+	"github.com/thosaphol/assessment-tax/pkg/money"
+)
+
+// Calculation is a saved calculation. Input is the request, or the CSV row
+// as one, and Output the tax returned for it.
+type Calculation struct {
+	ID        string          `json:"id"`
+	Input     json.RawMessage `json:"input"`
+	Setting   json.RawMessage `json:"setting"`
+	Output    json.RawMessage `json:"output"`
+	CreatedAt string          `json:"createdAt"`
+}
+
+// CalculationSetting is the snapshot of the deductions and the brackets a
+// calculation was made with. Version is the settings version of the
+// deductions.
+type CalculationSetting struct {
+	Version           int64              `json:"version"`
+	PersonalDeduction money.Money        `json:"personalDeduction"`
+	KReceipt          money.Money        `json:"kReceipt"`
+	Household         HouseholdDeduction `json:"household"`
+	TaxBrackets       TaxBrackets        `json:"taxBrackets"`
+}
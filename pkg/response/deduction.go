@@ -1,9 +1,19 @@
 package response
 
+import "github.com/thosaphol/assessment-tax/pkg/money"
+
 type PersonalDeduction struct {
-	PersonalDeduction float64 `json:"personalDeduction"`
+	PersonalDeduction money.Money `json:"personalDeduction"`
 }
 
 type KReceiptDeduction struct {
-	KReceipt float64 `json:"kReceipt"`
+	KReceipt money.Money `json:"kReceipt"`
+}
+
+type HouseholdDeduction struct {
+	Spouse     money.Money `json:"spouse"`
+	Child      money.Money `json:"child"`
+	ChildBonus money.Money `json:"childBonus"`
+	Parent     money.Money `json:"parent"`
+	Disability money.Money `json:"disability"`
 }
@@ -1,22 +1,215 @@
 package response
 
+import "github.com/thosaphol/assessment-tax/pkg/money"
+
 type Tax struct {
-	Tax       float64    `json:"tax"`
-	TaxLevels []TaxLevel `json:"taxLevel"`
+	TaxYear    int         `json:"taxYear"`
+	Period     string      `json:"period,omitempty"`
+	Tax        money.Money `json:"tax"`
+	TaxLevels  []TaxLevel  `json:"taxLevel"`
+	Incomes    []Income    `json:"incomes,omitempty"`
+	Allowances []Allowance `json:"allowances,omitempty"`
+	TaxMethod  string      `json:"taxMethod,omitempty"`
+	TaxMethods []TaxMethod `json:"taxMethods,omitempty"`
+	Rates
+	Credits
+	Late
+	FinalTax  *FinalTax  `json:"finalTax,omitempty"`
+	Elections *Elections `json:"elections,omitempty"`
+	LumpSum   *LumpSum   `json:"lumpSum,omitempty"`
+	Steps     []Step     `json:"steps,omitempty"`
+	// SettingsVersion identifies the deductions calculated with, to
+	// calculate again with the same ones.
+	SettingsVersion int64 `json:"settingsVersion"`
+	// CalculationID is reported only when the calculation is saved.
+	CalculationID string `json:"calculationId,omitempty"`
+}
+
+// LumpSum breaks down the separate tax of the lump sum, reported only when
+// one is given. Its tax, less the wht, is included in the tax.
+type LumpSum struct {
+	Amount           money.Money `json:"amount"`
+	YearsOfService   int         `json:"yearsOfService"`
+	ServiceDeduction money.Money `json:"serviceDeduction"`
+	HalfDeduction    money.Money `json:"halfDeduction"`
+	NetIncome        money.Money `json:"netIncome"`
+	Tax              money.Money `json:"tax"`
+	Wht              money.Money `json:"wht"`
+	TaxLevels        []TaxLevel  `json:"taxLevel"`
+}
+
+// FinalTax lists the incomes taxed at source as final tax and left out of
+// the return, reported only when there are any.
+type FinalTax struct {
+	Incomes []FinalTaxIncome `json:"incomes"`
+	Tax     money.Money      `json:"tax"`
+}
+
+type FinalTaxIncome struct {
+	Category string      `json:"category"`
+	Amount   money.Money `json:"amount"`
+	Wht      money.Money `json:"wht"`
+}
+
+// Elections compares electing final tax with including the incomes in the
+// return, reported only when asked to compare. Total is the tax of the
+// return, less any refund, plus the final tax.
+type Elections struct {
+	Recommended string     `json:"recommended"`
+	Options     []Election `json:"options"`
+}
+
+type Election struct {
+	Election  string      `json:"election"`
+	Tax       money.Money `json:"tax"`
+	TaxRefund money.Money `json:"taxRefund"`
+	FinalTax  money.Money `json:"finalTax"`
+	Total     money.Money `json:"total"`
+}
+
+// Step is one line of the calculation, reported only when asked to explain.
+type Step struct {
+	Step          string      `json:"step"`
+	Amount        money.Money `json:"amount"`
+	Description   string      `json:"description"`
+	DescriptionTh string      `json:"descriptionTh"`
+}
+
+// Rates reports where the net income sits in the brackets. Headroom is left
+// out in the top bracket, UnusedDeduction unless the deductions exceed the
+// income.
+type Rates struct {
+	NetIncome          money.Money  `json:"netIncome"`
+	UnusedDeduction    *money.Money `json:"unusedDeduction,omitempty"`
+	MarginalRate       int          `json:"marginalRate"`
+	Headroom           *money.Money `json:"headroom,omitempty"`
+	EffectiveRate      float64      `json:"effectiveRate"`
+	EffectiveRateOnNet float64      `json:"effectiveRateOnNet"`
+}
+
+// Credits are reported only when there is tax withheld from the incomes,
+// dividends to credit or foreign tax paid.
+type Credits struct {
+	IncomeWht        *money.Money `json:"incomeWht,omitempty"`
+	DividendWht      *money.Money `json:"dividendWht,omitempty"`
+	DividendCredit   *money.Money `json:"dividendCredit,omitempty"`
+	ForeignTaxCredit *money.Money `json:"foreignTaxCredit,omitempty"`
+}
+
+// Late is reported only when a filing date is given.
+type Late struct {
+	DueDate      string       `json:"dueDate,omitempty"`
+	Surcharge    *money.Money `json:"surcharge,omitempty"`
+	Penalty      *money.Money `json:"penalty,omitempty"`
+	TotalPayable *money.Money `json:"totalPayable,omitempty"`
+}
+type TaxMethod struct {
+	Method string      `json:"method"`
+	Tax    money.Money `json:"tax"`
+}
+type Income struct {
+	Category string      `json:"category"`
+	Amount   money.Money `json:"amount"`
+	Expense  money.Money `json:"expense"`
+	Net      money.Money `json:"net"`
+}
+type Allowance struct {
+	AllowanceType string       `json:"allowanceType"`
+	Claimed       money.Money  `json:"claimed"`
+	Allowed       money.Money  `json:"allowed"`
+	Cap           *money.Money `json:"cap,omitempty"`
+	Unused        *money.Money `json:"unused,omitempty"`
 }
 type TaxLevel struct {
-	Level string  `json:"level"`
-	Tax   float64 `json:"tax"`
+	Level   string      `json:"level"`
+	Rate    int         `json:"rate"`
+	Taxable money.Money `json:"taxable"`
+	Tax     money.Money `json:"tax"`
 }
 type TaxWithRefund struct {
 	Tax
-	TaxRefund float64 `json:"taxRefund"`
+	TaxRefund money.Money `json:"taxRefund"`
+}
+
+type Filing struct {
+	Filer string `json:"filer"`
+	TaxWithRefund
+}
+type FilingOption struct {
+	Option    string      `json:"option"`
+	Tax       money.Money `json:"tax"`
+	TaxRefund money.Money `json:"taxRefund"`
+	Filings   []Filing    `json:"filings"`
+}
+type HouseholdTax struct {
+	Recommended string         `json:"recommended"`
+	Options     []FilingOption `json:"options"`
+}
+
+type GrossUp struct {
+	TotalIncome money.Money `json:"totalIncome"`
+	Tax
+}
+
+type PayrollMonth struct {
+	Month  int         `json:"month"`
+	Salary money.Money `json:"salary"`
+	Bonus  money.Money `json:"bonus"`
+	Wht    money.Money `json:"wht"`
+}
+type Payroll struct {
+	TaxYear   int            `json:"taxYear"`
+	Month     int            `json:"month"`
+	Wht       money.Money    `json:"wht"`
+	AnnualTax money.Money    `json:"annualTax"`
+	Schedule  []PayrollMonth `json:"schedule"`
+	// SettingsVersion identifies the deductions calculated with.
+	SettingsVersion int64 `json:"settingsVersion"`
+}
+
+type ScenarioTax struct {
+	Name          string      `json:"name"`
+	TotalIncome   money.Money `json:"totalIncome"`
+	NetIncome     money.Money `json:"netIncome"`
+	Tax           money.Money `json:"tax"`
+	TaxRefund     money.Money `json:"taxRefund"`
+	MarginalRate  int         `json:"marginalRate"`
+	EffectiveRate float64     `json:"effectiveRate"`
+	TaxDelta      money.Money `json:"taxDelta"`
+	Contribution  money.Money `json:"contribution"`
+	SavingPerBaht float64     `json:"savingPerBaht"`
+}
+type Scenarios struct {
+	Base      ScenarioTax   `json:"base"`
+	Scenarios []ScenarioTax `json:"scenarios"`
+	// SettingsVersion identifies the deductions calculated with.
+	SettingsVersion int64 `json:"settingsVersion"`
+}
+
+type Allocation struct {
+	AllowanceType string      `json:"allowanceType"`
+	Amount        money.Money `json:"amount"`
+}
+type Optimize struct {
+	Allocations []Allocation `json:"allocations"`
+	Invested    money.Money  `json:"invested"`
+	Unallocated money.Money  `json:"unallocated"`
+	TaxSaving   money.Money  `json:"taxSaving"`
+	Before      Tax          `json:"before"`
+	After       Tax          `json:"after"`
 }
 
 type TaxWithIncome struct {
-	TotalIncome float64 `json:"totalIncome"`
-	Tax         float64 `json:"tax"`
-	TaxRefund   float64 `json:"taxRefund"`
+	TaxYear     int         `json:"taxYear"`
+	TotalIncome money.Money `json:"totalIncome"`
+	Tax         money.Money `json:"tax"`
+	TaxRefund   money.Money `json:"taxRefund"`
+	TaxMethod   string      `json:"taxMethod,omitempty"`
+	TaxMethods  []TaxMethod `json:"taxMethods,omitempty"`
+	Late
+	Steps           []Step `json:"steps,omitempty"`
+	SettingsVersion int64  `json:"settingsVersion"`
+	CalculationID   string `json:"calculationId,omitempty"`
 }
 type Taxes struct {
 	Taxes []TaxWithIncome `json:"taxes"`
@@ -0,0 +1,15 @@
+package response
+
+import "github.com/thosaphol/assessment-tax/pkg/money"
+
+type TaxBrackets struct {
+	TaxYear     int          `json:"taxYear"`
+	TaxBrackets []TaxBracket `json:"taxBrackets"`
+}
+
+type TaxBracket struct {
+	Level   string       `json:"level"`
+	Lower   money.Money  `json:"lower"`
+	Upper   *money.Money `json:"upper,omitempty"`
+	TaxRate int          `json:"taxRate"`
+}
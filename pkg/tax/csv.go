@@ -0,0 +1,228 @@
+package tax
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/thosaphol/assessment-tax/pkg/engine"
+	"github.com/thosaphol/assessment-tax/pkg/money"
+	"github.com/thosaphol/assessment-tax/pkg/repo"
+	"github.com/thosaphol/assessment-tax/pkg/request"
+	resp "github.com/thosaphol/assessment-tax/pkg/response"
+	"github.com/thosaphol/assessment-tax/utils"
+)
+
+var requiredCSVColumns = []string{"totalIncome", "wht", "donation"}
+
+func (h *Handler) CalculationCSV(c echo.Context) error {
+	explain, err := boolParam(c, "explain")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Err{err.Error()})
+	}
+	save, err := boolParam(c, "save")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Err{err.Error()})
+	}
+	version, err := versionParam(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Err{err.Error()})
+	}
+
+	file, err := c.FormFile("taxFile")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Err{err.Error()})
+	}
+	if ext := utils.GetFileExt(file.Filename); ext != ".csv" {
+		return c.JSON(http.StatusBadRequest, Err{"File extension must is .csv"})
+	}
+	src, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	reader := utils.NewCsvReader(src)
+	s := reader.ReadLine()
+	if !s {
+		_, err = reader.GetLine()
+		return c.JSON(http.StatusBadRequest, Err{err.Error()})
+	}
+
+	hRecord, _ := reader.GetLine()
+	err = validateHeadCSV(hRecord)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Err{err.Error()})
+	}
+
+	var ins []engine.Input
+	var years []int
+	for reader.ReadLine() {
+		rec, err := reader.GetLine()
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, err)
+		}
+
+		if len(rec) != len(hRecord) {
+			return c.JSON(http.StatusBadRequest, Err{fmt.Sprintf("Some rows have columns not equal to %d.", len(hRecord))})
+		}
+
+		in, err := separateRecord(hRecord, rec)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, Err{err.Error()})
+		}
+
+		ins = append(ins, in)
+		years = append(years, in.TaxYear)
+	}
+
+	eng, err := h.engine(version, years...)
+	if err != nil {
+		return c.JSON(engineStatus(err), Err{err.Error()})
+	}
+
+	var taxes []resp.TaxWithIncome
+	var calcs []repo.Calculation
+	for _, in := range ins {
+		r, err := eng.Calculate(in)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, Err{err.Error()})
+		}
+
+		t := resp.TaxWithIncome{TaxYear: r.TaxYear, TotalIncome: r.TotalIncome, Tax: r.Tax, TaxRefund: r.TaxRefund, SettingsVersion: r.SettingsVersion}
+		t.TaxMethod, t.TaxMethods = toTaxMethods(r)
+		t.Late = toLate(r)
+		if explain {
+			t.Steps = toSteps(eng.Explain(in, r))
+		}
+		if save {
+			calc, err := newCalculation(csvIncomeExpense(in), eng, r.TaxYear, t)
+			if err != nil {
+				return c.JSON(http.StatusInternalServerError, Err{err.Error()})
+			}
+			calcs = append(calcs, calc)
+		}
+		taxes = append(taxes, t)
+	}
+
+	// the rows are saved only when all of them are calculated
+	if save {
+		ids, err := h.store.SaveCalculations(calcs)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, Err{err.Error()})
+		}
+		for i, id := range ids {
+			taxes[i].CalculationID = id
+		}
+	}
+
+	return c.JSON(http.StatusOK, resp.Taxes{Taxes: taxes})
+}
+
+// validateHeadCSV requires totalIncome, wht and donation, and allows taxYear,
+// filingDate, paymentDate and an income column for each category 40(1) to
+// 40(8).
+func validateHeadCSV(headers []string) error {
+	errHead := errors.New("Header of content is 'totalIncome,wht,donation' with optional 'taxYear', 'filingDate', 'paymentDate' and '40(1)' to '40(8)' columns only")
+
+	seen := map[string]bool{}
+	for _, h := range headers {
+		if seen[h] {
+			return errHead
+		}
+		seen[h] = true
+
+		switch {
+		case h == "totalIncome", h == "wht", h == "donation", h == "taxYear", h == "filingDate", h == "paymentDate":
+		case engine.IsIncomeCategory(h):
+		default:
+			return errHead
+		}
+	}
+	for _, col := range requiredCSVColumns {
+		if !seen[col] {
+			return errHead
+		}
+	}
+	return nil
+}
+
+// csvIncomeExpense returns the input read from a CSV row as a calculation
+// request.
+func csvIncomeExpense(in engine.Input) request.IncomeExpense {
+	ie := request.IncomeExpense{TaxYear: in.TaxYear, TotalIncome: in.TotalIncome, Wht: in.Wht, FilingDate: formatDate(in.FilingDate), PaymentDate: formatDate(in.PaymentDate)}
+	for _, inc := range in.Incomes {
+		ie.Incomes = append(ie.Incomes, request.Income{Category: inc.Category, Amount: inc.Amount})
+	}
+	for _, alw := range in.Allowances {
+		ie.Allowances = append(ie.Allowances, request.Allowance{AllowanceType: alw.AllowanceType, Amount: alw.Amount})
+	}
+	return ie
+}
+
+// formatDate formats d as a request date, empty when not given.
+func formatDate(d time.Time) string {
+	if d.IsZero() {
+		return ""
+	}
+	return d.Format(dateLayout)
+}
+
+func separateRecord(headers, record []string) (engine.Input, error) {
+	var in engine.Input
+	if len(record) != len(headers) {
+		return in, fmt.Errorf("row has columns not equal to %d.", len(headers))
+	}
+
+	for i, h := range headers {
+		switch h {
+		case "totalIncome":
+			income, err := money.Parse(record[i])
+			if err != nil {
+				return in, errors.New("Income column has format incorrect")
+			}
+			in.TotalIncome = income
+		case "wht":
+			wht, err := money.Parse(record[i])
+			if err != nil {
+				return in, errors.New("Wht column has format incorrect")
+			}
+			in.Wht = wht
+		case "donation":
+			donate, err := money.Parse(record[i])
+			if err != nil {
+				return in, errors.New("Donate column has format incorrect")
+			}
+			in.Allowances = append(in.Allowances, engine.Allowance{AllowanceType: "donation", Amount: donate})
+		case "taxYear":
+			year, err := strconv.Atoi(record[i])
+			if err != nil {
+				return in, errors.New("TaxYear column has format incorrect")
+			}
+			in.TaxYear = year
+		case "filingDate", "paymentDate":
+			if record[i] == "" {
+				continue
+			}
+			d, err := time.Parse(dateLayout, record[i])
+			if err != nil {
+				return in, fmt.Errorf("%s column has format incorrect", h)
+			}
+			if h == "filingDate" {
+				in.FilingDate = d
+			} else {
+				in.PaymentDate = d
+			}
+		default:
+			amount, err := money.Parse(record[i])
+			if err != nil {
+				return in, fmt.Errorf("%s column has format incorrect", h)
+			}
+			in.Incomes = append(in.Incomes, engine.Income{Category: h, Amount: amount})
+		}
+	}
+	return in, nil
+}
@@ -2,17 +2,21 @@ package tax
 
 import (
 	"errors"
-	"math"
+	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/labstack/echo/v4"
+	"github.com/thosaphol/assessment-tax/pkg/engine"
+	"github.com/thosaphol/assessment-tax/pkg/money"
 	"github.com/thosaphol/assessment-tax/pkg/repo"
 	"github.com/thosaphol/assessment-tax/pkg/request"
 	resp "github.com/thosaphol/assessment-tax/pkg/response"
-	"github.com/thosaphol/assessment-tax/utils"
 )
 
+const dateLayout = "2006-01-02"
+
 type Err struct {
 	Message string `json:"message"`
 }
@@ -26,261 +30,553 @@ func New(db repo.Storer) *Handler {
 }
 
 func (h *Handler) Calculation(c echo.Context) error {
+	explain, err := boolParam(c, "explain")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Err{err.Error()})
+	}
+	compare, err := boolParam(c, "compareElections")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Err{err.Error()})
+	}
+	save, err := boolParam(c, "save")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Err{err.Error()})
+	}
+	version, err := versionParam(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Err{err.Error()})
+	}
 
 	var ie request.IncomeExpense
-	err := c.Bind(&ie)
+	err = c.Bind(&ie)
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, Err{err.Error()})
 	}
 
-	err = validateIncomeExpense(ie)
+	err = ie.Validate()
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, Err{err.Error()})
 	}
-	personalD, err := h.store.PersonalDeduction()
+
+	in := toInput(ie)
+	err = engine.Validate(in)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, Err{err.Error()})
+		return c.JSON(http.StatusBadRequest, Err{err.Error()})
 	}
-	maxKReceipt, err := h.store.KReceiptDeduction()
 
-	alwTotal := calculateAllowance(ie.Allowances, maxKReceipt)
-	iNet := calculateIncome(ie.TotalIncome, alwTotal, personalD)
+	eng, err := h.engine(version, in.TaxYear)
+	if err != nil {
+		return c.JSON(engineStatus(err), Err{err.Error()})
+	}
 
-	var tConsts = GetTaxConsts()
-	var tLevels []resp.TaxLevel
-	ttax := 0.0
-	for _, tConst := range tConsts {
-		var tLevel = resp.TaxLevel{Level: tConst.Level}
-		if iNet > float64(tConst.Lower) {
-
-			if iNet > tConst.Upper {
-				taxInLevel := calculateTaxLevel(tConst.Upper, tConst)
-				ttax += taxInLevel
-
-				tLevel.Tax = taxInLevel
-			} else {
-				taxInLevel := calculateTaxLevel(iNet, tConst)
-				ttax += taxInLevel
-
-				tLevel.Tax = taxInLevel
-			}
-		}
-		tLevels = append(tLevels, tLevel)
+	r, err := eng.Calculate(in)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Err{err.Error()})
 	}
 
-	if ttax >= ie.Wht {
-		return c.JSON(http.StatusOK, resp.Tax{Tax: ttax - ie.Wht, TaxLevels: tLevels})
+	t := toTax(r)
+	if explain {
+		t.Steps = toSteps(eng.Explain(in, r))
 	}
-	return c.JSON(http.StatusOK, resp.TaxWithRefund{Tax: resp.Tax{Tax: 0, TaxLevels: tLevels},
-		TaxRefund: ie.Wht - ttax})
+	if compare {
+		er, err := eng.CompareElections(in)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, Err{err.Error()})
+		}
+		t.Elections = toElections(er)
+	}
+	if save {
+		calc, err := newCalculation(ie, eng, r.TaxYear, taxBody(t, r))
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, Err{err.Error()})
+		}
+		ids, err := h.store.SaveCalculations([]repo.Calculation{calc})
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, Err{err.Error()})
+		}
+		t.CalculationID = ids[0]
+	}
+	return c.JSON(http.StatusOK, taxBody(t, r))
 }
 
-func calculateAllowance(alws []request.Allowance, maxKReceipt float64) float64 {
-	alwKReceipt := sumKReceipt(alws, maxKReceipt)
-	alwDonate := sumDonation(alws)
-	return alwKReceipt + alwDonate
+// taxBody reports the refund only when there is one.
+func taxBody(t resp.Tax, r engine.Result) any {
+	if r.TaxRefund.IsZero() {
+		return t
+	}
+	return resp.TaxWithRefund{Tax: t, TaxRefund: r.TaxRefund}
 }
 
-func validateIncomeExpense(ie request.IncomeExpense) error {
-	err := validateAllowance(ie.Allowances)
+// CalculationHousehold compares a married couple filing separately with
+// filing jointly.
+func (h *Handler) CalculationHousehold(c echo.Context) error {
+	var hie request.HouseholdIncomeExpense
+	err := c.Bind(&hie)
 	if err != nil {
-		return err
+		return c.JSON(http.StatusBadRequest, Err{err.Error()})
 	}
-	err = validateIncome(ie.TotalIncome)
+
+	err = hie.Validate()
 	if err != nil {
-		return err
+		return c.JSON(http.StatusBadRequest, Err{err.Error()})
 	}
 
-	err = validateWht(ie.TotalIncome, ie.Wht)
+	taxpayer, spouse := toInput(hie.Taxpayer), toInput(hie.Spouse)
+	version, err := versionParam(c)
 	if err != nil {
-		return err
+		return c.JSON(http.StatusBadRequest, Err{err.Error()})
+	}
+	eng, err := h.engine(version, taxpayer.TaxYear, spouse.TaxYear)
+	if err != nil {
+		return c.JSON(engineStatus(err), Err{err.Error()})
 	}
-	return nil
-}
 
-func validateAllowance(alws []request.Allowance) error {
-	for _, alw := range alws {
-		if alw.Amount < 0 {
-			return errors.New("Amount allowance must greater than 0.")
-		}
-		switch alw.AllowanceType {
-		case "donation", "k-receipt":
-			continue
-		default:
-			return errors.New("AllowanceType is 'donation' or 'k-receipt' only")
-		}
+	fr, err := eng.CompareFiling(taxpayer, spouse)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Err{err.Error()})
 	}
-	return nil
+
+	separate := resp.FilingOption{Option: engine.FilingSeparate, Filings: []resp.Filing{
+		toFiling("taxpayer", fr.Taxpayer),
+		toFiling("spouse", fr.Spouse),
+	}}
+	separate.Tax = fr.Taxpayer.Tax.Add(fr.Spouse.Tax)
+	separate.TaxRefund = fr.Taxpayer.TaxRefund.Add(fr.Spouse.TaxRefund)
+	joint := resp.FilingOption{Option: engine.FilingJoint, Tax: fr.Joint.Tax, TaxRefund: fr.Joint.TaxRefund, Filings: []resp.Filing{
+		toFiling("joint", fr.Joint),
+	}}
+	return c.JSON(http.StatusOK, resp.HouseholdTax{Recommended: fr.Recommended, Options: []resp.FilingOption{separate, joint}})
 }
 
-func validateWht(income, wht float64) error {
-	if wht < 0 || wht > income {
-		return errors.New("Wht must be in the range 0 to TotalIncome.")
+// CalculationScenarios compares variations of an income with the base.
+func (h *Handler) CalculationScenarios(c echo.Context) error {
+	var sc request.Scenarios
+	err := c.Bind(&sc)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Err{err.Error()})
 	}
-	return nil
-}
-func validateIncome(income float64) error {
-	if income < 0 {
-		return errors.New("TotalIncome must have a starting value of 0.")
+
+	err = sc.Validate()
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Err{err.Error()})
+	}
+
+	base := toInput(sc.Base)
+	years := []int{base.TaxYear}
+	var scenarios []engine.Scenario
+	for _, s := range sc.Scenarios {
+		in := toInput(s.Apply(sc.Base))
+		scenarios = append(scenarios, engine.Scenario{Name: s.Name, Input: in})
+		years = append(years, in.TaxYear)
+	}
+
+	version, err := versionParam(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Err{err.Error()})
+	}
+	eng, err := h.engine(version, years...)
+	if err != nil {
+		return c.JSON(engineStatus(err), Err{err.Error()})
+	}
+
+	br, srs, err := eng.CompareScenarios(base, scenarios)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Err{err.Error()})
+	}
+
+	t := resp.Scenarios{Base: toScenarioTax("base", br), SettingsVersion: eng.Setting().Version}
+	for _, sr := range srs {
+		st := toScenarioTax(sr.Name, sr.Result)
+		st.TaxDelta, st.Contribution, st.SavingPerBaht = sr.TaxDelta, sr.Contribution, sr.SavingPerBaht
+		t.Scenarios = append(t.Scenarios, st)
 	}
-	return nil
+	return c.JSON(http.StatusOK, t)
 }
 
-func calculateTaxLevel(income float64, tConst TaxConst) (taxLevel float64) {
-	taxInLevel := (income - tConst.Lower) * float64(tConst.TaxRate) / 100
-	return float64(taxInLevel)
+func toScenarioTax(name string, r engine.Result) resp.ScenarioTax {
+	return resp.ScenarioTax{Name: name, TotalIncome: r.TotalIncome, NetIncome: r.NetIncome, Tax: r.Tax, TaxRefund: r.TaxRefund, MarginalRate: r.MarginalRate, EffectiveRate: r.EffectiveRate}
 }
 
-func (h *Handler) CalculationCSV(c echo.Context) error {
-	file, err := c.FormFile("taxFile")
+// CalculationOptimize recommends how to allocate a budget across the
+// tax-saving allowances.
+func (h *Handler) CalculationOptimize(c echo.Context) error {
+	var o request.Optimize
+	err := c.Bind(&o)
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, Err{err.Error()})
 	}
-	if ext := utils.GetFileExt(file.Filename); ext != ".csv" {
-		return c.JSON(http.StatusBadRequest, Err{"File extension must is .csv"})
+
+	err = o.Validate()
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Err{err.Error()})
 	}
-	src, err := file.Open()
+
+	in := toInput(o.IncomeExpense)
+	version, err := versionParam(c)
 	if err != nil {
-		return err
+		return c.JSON(http.StatusBadRequest, Err{err.Error()})
+	}
+	eng, err := h.engine(version, in.TaxYear)
+	if err != nil {
+		return c.JSON(engineStatus(err), Err{err.Error()})
 	}
-	defer src.Close()
 
-	reader := utils.NewCsvReader(src)
-	s := reader.ReadLine()
-	if !s {
-		_, err = reader.GetLine()
+	or, err := eng.Optimize(in, o.Budget, o.Instruments)
+	if err != nil {
 		return c.JSON(http.StatusBadRequest, Err{err.Error()})
 	}
 
-	hRecord, _ := reader.GetLine()
-	err = validateHeadCSV(hRecord)
+	t := resp.Optimize{Allocations: []resp.Allocation{}, Invested: or.Invested, Unallocated: or.Unallocated, TaxSaving: or.TaxSaving, Before: toTax(or.Before), After: toTax(or.After)}
+	for _, a := range or.Allocations {
+		t.Allocations = append(t.Allocations, resp.Allocation{AllowanceType: a.AllowanceType, Amount: a.Amount})
+	}
+	return c.JSON(http.StatusOK, t)
+}
+
+// CalculationGrossUp solves for the total income of which the income after
+// tax reaches the requested net income.
+func (h *Handler) CalculationGrossUp(c echo.Context) error {
+	var g request.GrossUp
+	err := c.Bind(&g)
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, Err{err.Error()})
 	}
 
-	personalD, err := h.store.PersonalDeduction()
+	err = g.Validate()
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, Err{err.Error()})
+		return c.JSON(http.StatusBadRequest, Err{err.Error()})
 	}
 
-	var taxes []resp.TaxWithIncome
-	for reader.ReadLine() {
-		rec, err := reader.GetLine()
-		if err != nil {
-			return c.JSON(http.StatusBadRequest, err)
-		}
+	in := toInput(g.IncomeExpense())
+	version, err := versionParam(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Err{err.Error()})
+	}
+	eng, err := h.engine(version, in.TaxYear)
+	if err != nil {
+		return c.JSON(engineStatus(err), Err{err.Error()})
+	}
 
-		if len(rec) != 3 {
-			return c.JSON(http.StatusBadRequest, Err{"Some rows have columns not equal to 3."})
-		}
+	r, err := eng.GrossUp(g.NetIncome, g.Category, in)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Err{err.Error()})
+	}
+	return c.JSON(http.StatusOK, resp.GrossUp{TotalIncome: r.TotalIncome, Tax: toTax(r)})
+}
 
-		var taxInc resp.TaxWithIncome
+// CalculationPayroll returns the monthly WHT of an employee for the month
+// asked and the schedule of the year.
+func (h *Handler) CalculationPayroll(c echo.Context) error {
+	var p request.Payroll
+	err := c.Bind(&p)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Err{err.Error()})
+	}
 
-		income, wht, donate, err := separateRecord(rec)
-		if err != nil {
-			return c.JSON(http.StatusBadRequest, Err{err.Error()})
-		}
-		donate = getMinDonation(donate)
+	err = p.Validate()
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Err{err.Error()})
+	}
 
-		iNet := calculateIncome(income, donate, personalD)
-		netTax, refund := calculateTaxNet(iNet, wht)
+	version, err := versionParam(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Err{err.Error()})
+	}
+	eng, err := h.engine(version, p.TaxYear)
+	if err != nil {
+		return c.JSON(engineStatus(err), Err{err.Error()})
+	}
 
-		taxInc = resp.TaxWithIncome{TotalIncome: income, Tax: netTax, TaxRefund: refund}
+	pr, err := eng.CalculatePayroll(toPayroll(p))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Err{err.Error()})
+	}
 
-		taxes = append(taxes, taxInc)
+	var schedule []resp.PayrollMonth
+	for _, m := range pr.Schedule {
+		schedule = append(schedule, resp.PayrollMonth{Month: m.Month, Salary: m.Salary, Bonus: m.Bonus, Wht: m.Wht})
+	}
+	return c.JSON(http.StatusOK, resp.Payroll{TaxYear: pr.TaxYear, Month: pr.Month, Wht: pr.Wht, AnnualTax: pr.AnnualTax, Schedule: schedule, SettingsVersion: eng.Setting().Version})
+}
 
+func toFiling(filer string, r engine.Result) resp.Filing {
+	return resp.Filing{Filer: filer, TaxWithRefund: resp.TaxWithRefund{Tax: toTax(r), TaxRefund: r.TaxRefund}}
+}
+
+func toTax(r engine.Result) resp.Tax {
+	tLevels := toTaxLevels(r.TaxLevels)
+
+	var incomes []resp.Income
+	for _, inc := range r.Incomes {
+		incomes = append(incomes, resp.Income{Category: inc.Category, Amount: inc.Amount, Expense: inc.Expense, Net: inc.Net})
+	}
+
+	var alws []resp.Allowance
+	for _, alw := range r.Allowances {
+		a := resp.Allowance{AllowanceType: alw.AllowanceType, Claimed: alw.Claimed, Allowed: alw.Allowed, Cap: alw.Cap}
+		if !alw.Unused.IsZero() {
+			a.Unused = &alw.Unused
+		}
+		alws = append(alws, a)
 	}
 
-	return c.JSON(http.StatusOK, resp.Taxes{Taxes: taxes})
+	var t = resp.Tax{TaxYear: r.TaxYear, Tax: r.Tax, TaxLevels: tLevels, Incomes: incomes, Allowances: alws, SettingsVersion: r.SettingsVersion}
+	t.TaxMethod, t.TaxMethods = toTaxMethods(r)
+	t.Rates = resp.Rates{NetIncome: r.NetIncome, MarginalRate: r.MarginalRate, EffectiveRate: r.EffectiveRate, EffectiveRateOnNet: r.EffectiveRateOnNet}
+	if !r.Headroom.IsUnlimited() {
+		t.Rates.Headroom = &r.Headroom
+	}
+	if !r.UnusedDeduction.IsZero() {
+		t.Rates.UnusedDeduction = &r.UnusedDeduction
+	}
+	t.Credits = toCredits(r)
+	if len(r.FinalTaxItems) > 0 {
+		t.FinalTax = toFinalTax(r)
+	}
+	if r.LumpSum != nil {
+		t.LumpSum = toLumpSum(*r.LumpSum)
+	}
+	// the period is reported only for the half-year return
+	if r.Period == engine.PeriodHalfYear {
+		t.Period = r.Period
+	}
+	t.Late = toLate(r)
+	return t
 }
 
-func validateHeadCSV(headers []string) error {
-	if len(headers) != 3 {
-		return errors.New("Header of content is 'totalIncome,wht,donation' only")
+// toLate reports the late filing only when a filing date is given.
+func toLate(r engine.Result) resp.Late {
+	if r.DueDate.IsZero() {
+		return resp.Late{}
 	}
-	if headers[0] != "totalIncome" || headers[1] != "wht" || headers[2] != "donation" {
-		return errors.New("Header of content is 'totalIncome,wht,donation' only")
+	return resp.Late{DueDate: r.DueDate.Format(dateLayout), Surcharge: &r.Surcharge, Penalty: &r.Penalty, TotalPayable: &r.TotalPayable}
+}
+
+func toCredits(r engine.Result) resp.Credits {
+	var c resp.Credits
+	if !r.IncomeWht.IsZero() {
+		c.IncomeWht = &r.IncomeWht
+	}
+	if !r.DividendWht.IsZero() {
+		c.DividendWht = &r.DividendWht
+	}
+	if !r.DividendCredit.IsZero() {
+		c.DividendCredit = &r.DividendCredit
+	}
+	if !r.ForeignTaxCredit.IsZero() {
+		c.ForeignTaxCredit = &r.ForeignTaxCredit
 	}
-	return nil
+	return c
 }
 
-func calculateIncome(income, totalAlw, PersonalDed float64) float64 {
-	alwTotal := totalAlw
+func toTaxLevels(levels []engine.TaxLevel) []resp.TaxLevel {
+	var tLevels []resp.TaxLevel
+	for _, l := range levels {
+		tLevels = append(tLevels, resp.TaxLevel{Level: l.Level, Rate: l.Rate, Taxable: l.Taxable, Tax: l.Tax})
+	}
+	return tLevels
+}
 
-	iNet := income - PersonalDed
-	iNet -= alwTotal
-	return iNet
+func toLumpSum(ls engine.LumpSumResult) *resp.LumpSum {
+	return &resp.LumpSum{
+		Amount:           ls.Amount,
+		YearsOfService:   ls.YearsOfService,
+		ServiceDeduction: ls.ServiceDeduction,
+		HalfDeduction:    ls.HalfDeduction,
+		NetIncome:        ls.NetIncome,
+		Tax:              ls.Tax,
+		Wht:              ls.Wht,
+		TaxLevels:        toTaxLevels(ls.TaxLevels),
+	}
 }
 
-func sumDonation(alws []request.Allowance) float64 {
-	alwTotal := 0.0
-	for _, alw := range alws {
-		if alw.AllowanceType == "donation" {
-			alwTotal += alw.Amount
-		}
+func toFinalTax(r engine.Result) *resp.FinalTax {
+	ft := &resp.FinalTax{Tax: r.FinalTax}
+	for _, item := range r.FinalTaxItems {
+		ft.Incomes = append(ft.Incomes, resp.FinalTaxIncome{Category: item.Category, Amount: item.Amount, Wht: item.Wht})
 	}
-	alwTotal = getMinDonation(alwTotal)
-	return alwTotal
+	return ft
 }
-func sumKReceipt(alws []request.Allowance, maxKReceipt float64) float64 {
-	alwTotal := 0.0
-	for _, alw := range alws {
-		if alw.AllowanceType == "k-receipt" {
-			alwTotal += alw.Amount
-		}
+
+func toElections(er engine.ElectionResult) *resp.Elections {
+	return &resp.Elections{
+		Recommended: er.Recommended,
+		Options: []resp.Election{
+			toElection(engine.ElectionFinalTax, er.FinalTax, er.FinalTaxTotal),
+			toElection(engine.ElectionAnnual, er.Annual, er.AnnualTotal),
+		},
 	}
-	alwTotal = math.Min(alwTotal, maxKReceipt)
-	return alwTotal
 }
 
-func getMinDonation(donation float64) float64 {
-	return math.Min(donation, 100000)
-	// return math.Min(donation, 100000000000)
+func toElection(election string, r engine.Result, total money.Money) resp.Election {
+	return resp.Election{Election: election, Tax: r.Tax, TaxRefund: r.TaxRefund, FinalTax: r.FinalTax, Total: total}
+}
+
+func toSteps(steps []engine.Step) []resp.Step {
+	var rSteps []resp.Step
+	for _, s := range steps {
+		rSteps = append(rSteps, resp.Step{Step: s.Step, Amount: s.Amount, Description: s.En, DescriptionTh: s.Th})
+	}
+	return rSteps
 }
 
-func separateRecord(record []string) (float64, float64, float64, error) {
-	if len(record) != 3 {
-		return 0, 0, 0, errors.New("row has columns not equal to 3.")
+// boolParam reads a true or false query parameter, false when not given.
+func boolParam(c echo.Context, name string) (bool, error) {
+	v := c.QueryParam(name)
+	if v == "" {
+		return false, nil
 	}
-	income, err := strconv.ParseFloat(record[0], 64)
+	b, err := strconv.ParseBool(v)
 	if err != nil {
-		return 0, 0, 0, errors.New("Income column has format incorrect")
+		return false, fmt.Errorf("Invalid %s is required true or false", name)
 	}
+	return b, nil
+}
 
-	wht, err := strconv.ParseFloat(record[1], 64)
-	if err != nil {
-		return 0, 0, 0, errors.New("Wht column has format incorrect")
+// toTaxMethods reports both methods only when the gross income method had
+// to be compared.
+func toTaxMethods(r engine.Result) (string, []resp.TaxMethod) {
+	if !r.GrossIncomeApplied {
+		return "", nil
+	}
+	return r.TaxMethod, []resp.TaxMethod{
+		{Method: engine.MethodProgressive, Tax: r.ProgressiveTax},
+		{Method: engine.MethodGrossIncome, Tax: r.GrossIncomeTax},
 	}
+}
 
-	donate, err := strconv.ParseFloat(record[2], 64)
+// engine reads the deductions and the stored brackets of the years at
+// version, the current ones for 0.
+func (h *Handler) engine(version int64, years ...int) (*engine.Engine, error) {
+	d, err := h.store.DeductionSnapshot(version)
+	if version != 0 && errors.Is(err, repo.ErrNotFound) {
+		return nil, versionNotFoundError(version)
+	}
 	if err != nil {
-		return 0, 0, 0, errors.New("Donate column has format incorrect")
+		return nil, err
+	}
+
+	tConsts := map[int][]engine.TaxConst{}
+	for _, year := range years {
+		year = engine.ResolveTaxYear(year)
+		if _, ok := tConsts[year]; ok {
+			continue
+		}
+		stored, err := h.store.TaxBracketSnapshot(d.Version, year)
+		if err != nil {
+			return nil, err
+		}
+		if len(stored) > 0 {
+			tConsts[year] = stored
+		}
+	}
+
+	return engine.New(engine.Setting{Version: d.Version, Personal: d.Personal, MaxKReceipt: d.MaxKReceipt, Household: d.Household, TaxConsts: tConsts}), nil
+}
+
+// versionNotFoundError is returned by engine for a settings version that is
+// not stored.
+type versionNotFoundError int64
+
+func (v versionNotFoundError) Error() string {
+	return fmt.Sprintf("SettingsVersion '%d' is not found.", int64(v))
+}
+
+// engineStatus reports a settings version not stored as a bad request.
+func engineStatus(err error) int {
+	var notFound versionNotFoundError
+	if errors.As(err, &notFound) {
+		return http.StatusBadRequest
 	}
-	return income, wht, donate, nil
+	return http.StatusInternalServerError
 }
 
-func calculateTaxNet(incomeNet, wht float64) (tax float64, refund float64) {
-	var tConsts = GetTaxConsts()
-	ttax := 0.0
-	for i := 0; i < len(tConsts); i++ {
-		tConst := tConsts[i]
-		if incomeNet > float64(tConst.Lower) {
+// versionParam reads the settingsVersion query parameter, 0 for the current
+// settings when not given.
+func versionParam(c echo.Context) (int64, error) {
+	v := c.QueryParam("settingsVersion")
+	if v == "" {
+		return 0, nil
+	}
+	version, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || version <= 0 {
+		return 0, errors.New("Invalid settingsVersion is required a positive number")
+	}
+	return version, nil
+}
 
-			if incomeNet > float64(tConst.Upper) {
-				taxInLevel := (tConst.Upper - tConst.Lower) * float64(tConst.TaxRate) / 100
-				ttax += taxInLevel
+func toInput(ie request.IncomeExpense) engine.Input {
+	var incomes []engine.Income
+	for _, inc := range ie.Incomes {
+		incomes = append(incomes, engine.Income{Category: inc.Category, Amount: inc.Amount, ActualExpense: inc.ActualExpense, ForeignTax: inc.ForeignTax, Wht: inc.Wht, ElectFinalTax: inc.ElectFinalTax})
+	}
+	var dividends []engine.Dividend
+	for _, d := range ie.Dividends {
+		dividends = append(dividends, engine.Dividend{Amount: d.Amount, CorporateRate: d.CorporateRate, Wht: d.Wht})
+	}
+	return engine.Input{
+		TaxYear:            ie.TaxYear,
+		Period:             ie.Period,
+		TotalIncome:        ie.TotalIncome,
+		Incomes:            incomes,
+		Wht:                ie.Wht,
+		PrepaidHalfYearTax: ie.PrepaidHalfYearTax,
+		Dividends:          dividends,
+		IncludeDividends:   ie.IncludeDividends,
+		LumpSum:            toLumpSumInput(ie.LumpSum),
+		Allowances:         toAllowances(ie.Allowances),
+		Household:          toHousehold(ie.Household),
+		FilingDate:         parseDate(ie.FilingDate),
+		PaymentDate:        parseDate(ie.PaymentDate),
+	}
+}
 
-			} else {
-				diffLower := incomeNet - float64(tConst.Lower)
-				tax := diffLower * float64(tConst.TaxRate) / 100
-				ttax += tax
+func toLumpSumInput(ls *request.LumpSum) *engine.LumpSum {
+	if ls == nil {
+		return nil
+	}
+	return &engine.LumpSum{Amount: ls.Amount, YearsOfService: ls.YearsOfService, Wht: ls.Wht}
+}
 
-			}
-		}
+// parseDate reads a date already validated by the request, an empty one as
+// the zero time.
+func parseDate(s string) time.Time {
+	d, _ := time.Parse(dateLayout, s)
+	return d
+}
+
+func toAllowances(reqAlws []request.Allowance) []engine.Allowance {
+	var alws []engine.Allowance
+	for _, alw := range reqAlws {
+		alws = append(alws, engine.Allowance{AllowanceType: alw.AllowanceType, Amount: alw.Amount})
+	}
+	return alws
+}
+
+func toPayroll(p request.Payroll) engine.Payroll {
+	var bonuses []engine.Bonus
+	for _, b := range p.Bonuses {
+		bonuses = append(bonuses, engine.Bonus{Month: b.Month, Amount: b.Amount})
+	}
+	return engine.Payroll{
+		TaxYear:    p.TaxYear,
+		Month:      p.Month,
+		Salary:     p.Salary,
+		Bonuses:    bonuses,
+		Allowances: toAllowances(p.Allowances),
+		Household:  toHousehold(p.Household),
 	}
+}
 
-	if ttax >= wht {
-		return ttax - wht, 0
+func toHousehold(hh request.Household) engine.Household {
+	var children []engine.Child
+	for _, child := range hh.Children {
+		children = append(children, engine.Child{BirthYear: child.BirthYear})
+	}
+	return engine.Household{
+		Spouse:             hh.Spouse != nil && !hh.Spouse.HasIncome,
+		Children:           children,
+		Parents:            len(hh.Parents),
+		DisabledDependents: hh.DisabledDependents,
 	}
-	return 0, wht - ttax
 }
@@ -0,0 +1,97 @@
+package tax
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/thosaphol/assessment-tax/pkg/engine"
+	"github.com/thosaphol/assessment-tax/pkg/repo"
+	resp "github.com/thosaphol/assessment-tax/pkg/response"
+)
+
+// calculationIDPattern matches the random UUID a calculation is saved with,
+// which cannot be guessed from the IDs of others.
+var calculationIDPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// SavedCalculation returns a calculation saved with the save query parameter.
+func (h *Handler) SavedCalculation(c echo.Context) error {
+	id := c.Param("id")
+	if !calculationIDPattern.MatchString(id) {
+		return c.JSON(http.StatusBadRequest, Err{"ID must be a UUID."})
+	}
+
+	calc, err := h.store.Calculation(id)
+	if errors.Is(err, repo.ErrNotFound) {
+		return c.JSON(http.StatusNotFound, Err{"Calculation not found."})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, Err{err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, resp.Calculation{
+		ID:        calc.ID,
+		Input:     calc.Input,
+		Setting:   calc.Setting,
+		Output:    calc.Output,
+		CreatedAt: calc.CreatedAt.Format(time.RFC3339),
+	})
+}
+
+// newCalculation keeps input and output with the setting of eng for year,
+// to be saved.
+func newCalculation(input any, eng *engine.Engine, year int, output any) (repo.Calculation, error) {
+	setting, err := calculationSetting(eng, year)
+	if err != nil {
+		return repo.Calculation{}, err
+	}
+
+	var calc repo.Calculation
+	calc.Input, err = json.Marshal(input)
+	if err != nil {
+		return repo.Calculation{}, err
+	}
+	calc.Setting, err = json.Marshal(setting)
+	if err != nil {
+		return repo.Calculation{}, err
+	}
+	calc.Output, err = json.Marshal(output)
+	if err != nil {
+		return repo.Calculation{}, err
+	}
+	return calc, nil
+}
+
+func calculationSetting(eng *engine.Engine, year int) (resp.CalculationSetting, error) {
+	tConsts, err := eng.TaxConsts(year)
+	if err != nil {
+		return resp.CalculationSetting{}, err
+	}
+
+	s := eng.Setting()
+	var brackets []resp.TaxBracket
+	for _, tConst := range tConsts {
+		b := resp.TaxBracket{Level: tConst.Level, Lower: tConst.Lower, TaxRate: tConst.TaxRate}
+		if !tConst.Upper.IsUnlimited() {
+			upper := tConst.Upper
+			b.Upper = &upper
+		}
+		brackets = append(brackets, b)
+	}
+	return resp.CalculationSetting{
+		Version:           s.Version,
+		PersonalDeduction: s.Personal,
+		KReceipt:          s.MaxKReceipt,
+		Household: resp.HouseholdDeduction{
+			Spouse:     s.Household.Spouse,
+			Child:      s.Household.Child,
+			ChildBonus: s.Household.ChildBonus,
+			Parent:     s.Household.Parent,
+			Disability: s.Household.Disability,
+		},
+		TaxBrackets: resp.TaxBrackets{TaxYear: year, TaxBrackets: brackets},
+	}, nil
+}
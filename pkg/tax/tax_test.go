@@ -3,6 +3,7 @@ package tax
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"io"
 	"mime/multipart"
 	"net/http"
@@ -14,34 +15,105 @@ import (
 
 	"github.com/labstack/echo/v4"
 	"github.com/thosaphol/assessment-tax/pkg/deduction"
+	"github.com/thosaphol/assessment-tax/pkg/engine"
+	"github.com/thosaphol/assessment-tax/pkg/money"
+	"github.com/thosaphol/assessment-tax/pkg/repo"
 	req "github.com/thosaphol/assessment-tax/pkg/request"
 	resp "github.com/thosaphol/assessment-tax/pkg/response"
 )
 
 type StubStore struct {
 	deduction deduction.Deduction
-	err       error
+	version   int64
+	versions  map[int64]deduction.Deduction
+	taxConsts map[int][]engine.TaxConst
+	// versionTaxConsts are the brackets kept with the other versions.
+	versionTaxConsts map[int64]map[int][]engine.TaxConst
+	calculations     map[string]repo.Calculation
+	err              error
 }
 
 // Wallets implements Storer.
-func (stubStore StubStore) SetPersonalDeduction(amount float64) error {
+func (stubStore StubStore) SetPersonalDeduction(amount money.Money) error {
 	return stubStore.err
 }
-func (stubStore StubStore) PersonalDeduction() (float64, error) {
-	return stubStore.deduction.Personal, stubStore.err
+func (stubStore StubStore) SetKReceiptDeduction(amount money.Money) error {
+	return stubStore.err
+}
+
+func (stubStore StubStore) SetHouseholdDeduction(d engine.HouseholdDeduction) error {
+	return stubStore.err
 }
 
-func (stubStore StubStore) SetKReceiptDeduction(amount float64) error {
+func (stubStore StubStore) SetTaxBrackets(year int, tConsts []engine.TaxConst) error {
 	return stubStore.err
 }
 
-func (stubStore StubStore) KReceiptDeduction() (float64, error) {
-	return stubStore.deduction.MaxKReceipt, stubStore.err
+func (stubStore StubStore) TaxBrackets(year int) ([]engine.TaxConst, error) {
+	return stubStore.taxConsts[year], stubStore.err
+}
+
+func (stubStore StubStore) TaxBracketSnapshot(version int64, year int) ([]engine.TaxConst, error) {
+	if version != stubStore.version {
+		return stubStore.versionTaxConsts[version][year], stubStore.err
+	}
+	return stubStore.taxConsts[year], stubStore.err
+}
+
+func (stubStore StubStore) DeductionSnapshot(version int64) (repo.DeductionSnapshot, error) {
+	d := stubStore.deduction
+	if version == 0 {
+		version = stubStore.version
+	} else if version != stubStore.version {
+		var ok bool
+		d, ok = stubStore.versions[version]
+		if !ok {
+			return repo.DeductionSnapshot{}, repo.ErrNotFound
+		}
+	}
+	return repo.DeductionSnapshot{Version: version, Personal: d.Personal, MaxKReceipt: d.MaxKReceipt, Household: d.Household}, stubStore.err
+}
+
+func (stubStore StubStore) SaveCalculations(cs []repo.Calculation) ([]string, error) {
+	if stubStore.err != nil {
+		return nil, stubStore.err
+	}
+	var ids []string
+	for _, c := range cs {
+		c.ID = calculationID(len(stubStore.calculations) + 1)
+		stubStore.calculations[c.ID] = c
+		ids = append(ids, c.ID)
+	}
+	return ids, nil
+}
+
+func (stubStore StubStore) Calculation(id string) (repo.Calculation, error) {
+	c, ok := stubStore.calculations[id]
+	if !ok {
+		return repo.Calculation{}, repo.ErrNotFound
+	}
+	return c, stubStore.err
+}
+
+// calculationID is the UUID the stub store saves the nth calculation with.
+func calculationID(n int) string {
+	return fmt.Sprintf("00000000-0000-4000-8000-%012d", n)
 }
 
 var stubStore = StubStore{
-	deduction: deduction.Deduction{Personal: 60000, MaxKReceipt: 50000},
-	err:       nil,
+	deduction: deduction.Deduction{Personal: money.Baht(60000), MaxKReceipt: money.Baht(50000), Household: engine.HouseholdDeduction{
+		Spouse:     money.Baht(60000),
+		Child:      money.Baht(30000),
+		ChildBonus: money.Baht(60000),
+		Parent:     money.Baht(30000),
+		Disability: money.Baht(60000),
+	}},
+	err: nil,
+}
+
+func moneyPtr(f float64) *money.Money {
+	m := money.Baht(f)
+	return &m
 }
 
 func TestIncomeExpenseValidation(t *testing.T) {
@@ -55,7 +127,7 @@ func TestIncomeExpenseValidation(t *testing.T) {
 			name: "given amount allowance is less than 0 to calculate tax should return code 400 and message",
 			ie: req.IncomeExpense{
 				Allowances: []req.Allowance{
-					{AllowanceType: "donation", Amount: -1},
+					{AllowanceType: "donation", Amount: money.Baht(-1)},
 				},
 			},
 			wantCode: http.StatusBadRequest,
@@ -65,7 +137,7 @@ func TestIncomeExpenseValidation(t *testing.T) {
 			name: "given amount allowance is 0 to calculate tax should return code 200",
 			ie: req.IncomeExpense{
 				Allowances: []req.Allowance{
-					{AllowanceType: "donation", Amount: 0},
+					{AllowanceType: "donation", Amount: money.Baht(0)},
 				},
 			},
 			wantCode: http.StatusOK,
@@ -79,7 +151,7 @@ func TestIncomeExpenseValidation(t *testing.T) {
 				},
 			},
 			wantCode: http.StatusBadRequest,
-			wantBody: Err{Message: "AllowanceType is 'donation' or 'k-receipt' only"},
+			wantBody: Err{Message: "AllowanceType '' is not supported."},
 		},
 		{
 			name: "given income has allowance type isn't 'donation' to calculate tax should return code 400",
@@ -89,7 +161,7 @@ func TestIncomeExpenseValidation(t *testing.T) {
 				},
 			},
 			wantCode: http.StatusBadRequest,
-			wantBody: Err{Message: "AllowanceType is 'donation' or 'k-receipt' only"},
+			wantBody: Err{Message: "AllowanceType 'qwerty' is not supported."},
 		},
 		{
 			name: "given income has allowance type is 'donation' to calculate tax should return code 200",
@@ -105,8 +177,8 @@ func TestIncomeExpenseValidation(t *testing.T) {
 		{
 			name: "given income less than 0 to calculate tax should return 400 and message",
 			ie: req.IncomeExpense{
-				TotalIncome: -1,
-				Wht:         0.0,
+				TotalIncome: money.Baht(-1),
+				Wht:         money.Baht(0.0),
 			},
 			wantCode: http.StatusBadRequest,
 			wantBody: Err{Message: "TotalIncome must have a starting value of 0."},
@@ -114,16 +186,16 @@ func TestIncomeExpenseValidation(t *testing.T) {
 		{
 			name: "given income than 0 to calculate tax should return 200",
 			ie: req.IncomeExpense{
-				TotalIncome: 0,
-				Wht:         0.0,
+				TotalIncome: money.Baht(0),
+				Wht:         money.Baht(0.0),
 			},
 			wantCode: http.StatusOK,
 		},
 		{
 			name: "given withholding less than 0 to calculate tax should return code 400 and message",
 			ie: req.IncomeExpense{
-				TotalIncome: 0,
-				Wht:         -1.0,
+				TotalIncome: money.Baht(0),
+				Wht:         money.Baht(-1.0),
 			},
 			wantCode: http.StatusBadRequest,
 			wantBody: Err{Message: "Wht must be in the range 0 to TotalIncome."},
@@ -131,17 +203,142 @@ func TestIncomeExpenseValidation(t *testing.T) {
 		{
 			name: "given withholding greater than totalIncome to to calculate tax should return code 400 and message",
 			ie: req.IncomeExpense{
-				TotalIncome: 100,
-				Wht:         1000.0,
+				TotalIncome: money.Baht(100),
+				Wht:         money.Baht(1000.0),
 			},
 			wantCode: http.StatusBadRequest,
 			wantBody: Err{Message: "Wht must be in the range 0 to TotalIncome."},
 		},
+		{
+			name: "given unsupported tax year to calculate tax should return code 400 and message",
+			ie: req.IncomeExpense{
+				TaxYear:     2500,
+				TotalIncome: money.Baht(0),
+			},
+			wantCode: http.StatusBadRequest,
+			wantBody: Err{Message: "TaxYear is not supported."},
+		},
+		{
+			name: "given income category isn't 40(1) to 40(8) to calculate tax should return code 400 and message",
+			ie: req.IncomeExpense{
+				Incomes: []req.Income{
+					{Category: "salary", Amount: money.Baht(100)},
+				},
+			},
+			wantCode: http.StatusBadRequest,
+			wantBody: Err{Message: "Income category must be 40(1) to 40(8)."},
+		},
+		{
+			name: "given unknown period to calculate tax should return code 400 and message",
+			ie: req.IncomeExpense{
+				Period: "quarter",
+			},
+			wantCode: http.StatusBadRequest,
+			wantBody: Err{Message: "Period must be annual or half-year."},
+		},
+		{
+			name: "given total income in half-year period should return code 400 and message",
+			ie: req.IncomeExpense{
+				Period:      "half-year",
+				TotalIncome: money.Baht(1000000),
+			},
+			wantCode: http.StatusBadRequest,
+			wantBody: Err{Message: "TotalIncome is only counted in the annual period."},
+		},
+		{
+			name: "given filing date not in YYYY-MM-DD to calculate tax should return code 400 and message",
+			ie: req.IncomeExpense{
+				FilingDate: "05/04/2025",
+			},
+			wantCode: http.StatusBadRequest,
+			wantBody: Err{Message: "FilingDate: Invalid filingDate is required YYYY-MM-DD"},
+		},
+		{
+			name: "given payment date before filing date to calculate tax should return code 400 and message",
+			ie: req.IncomeExpense{
+				FilingDate:  "2025-04-05",
+				PaymentDate: "2025-04-01",
+			},
+			wantCode: http.StatusBadRequest,
+			wantBody: Err{Message: "PaymentDate must not be before FilingDate."},
+		},
+		{
+			name: "given parent younger than 60 to calculate tax should return code 400 and message",
+			ie: req.IncomeExpense{
+				Household: req.Household{Parents: []req.Parent{{Age: 59}}},
+			},
+			wantCode: http.StatusBadRequest,
+			wantBody: Err{Message: "Age: Invalid age is required 60 or more"},
+		},
+		{
+			name: "given parent income greater than 30,000 to calculate tax should return code 400 and message",
+			ie: req.IncomeExpense{
+				Household: req.Household{Parents: []req.Parent{{Age: 60, Income: money.Baht(30000.01)}}},
+			},
+			wantCode: http.StatusBadRequest,
+			wantBody: Err{Message: "Income: Invalid income is required 0.0 to 30,000.0"},
+		},
+		{
+			name: "given more than 4 parents to calculate tax should return code 400 and message",
+			ie: req.IncomeExpense{
+				Household: req.Household{Parents: []req.Parent{{Age: 60}, {Age: 61}, {Age: 62}, {Age: 63}, {Age: 64}}},
+			},
+			wantCode: http.StatusBadRequest,
+			wantBody: Err{Message: "Parents: Invalid parents is required at most 4"},
+		},
+		{
+			name: "given dividend corporate rate above 30 to calculate tax should return code 400 and message",
+			ie: req.IncomeExpense{
+				Dividends: []req.Dividend{{Amount: money.Baht(1000), CorporateRate: 31}},
+			},
+			wantCode: http.StatusBadRequest,
+			wantBody: Err{Message: "CorporateRate: Invalid corporateRate is required 0 to 30"},
+		},
+		{
+			name: "given foreign tax greater than the income to calculate tax should return code 400 and message",
+			ie: req.IncomeExpense{
+				Incomes: []req.Income{{Category: "40(8)", Amount: money.Baht(1000), ForeignTax: money.Baht(1001)}},
+			},
+			wantCode: http.StatusBadRequest,
+			wantBody: Err{Message: "ForeignTax must be in the range 0 to Amount income."},
+		},
+		{
+			name: "given lump sum with negative years of service to calculate tax should return code 400 and message",
+			ie: req.IncomeExpense{
+				LumpSum: &req.LumpSum{Amount: money.Baht(100000), YearsOfService: -1},
+			},
+			wantCode: http.StatusBadRequest,
+			wantBody: Err{Message: "YearsOfService: Invalid yearsOfService is required 0 or more"},
+		},
+		{
+			name: "given lump sum with service under 5 years to calculate tax should return code 400 and message",
+			ie: req.IncomeExpense{
+				LumpSum: &req.LumpSum{Amount: money.Baht(100000), YearsOfService: 4},
+			},
+			wantCode: http.StatusBadRequest,
+			wantBody: Err{Message: "YearsOfService must be at least 5 to tax the lump sum separately."},
+		},
+		{
+			name: "given child birth year not in the Buddhist era to calculate tax should return code 400 and message",
+			ie: req.IncomeExpense{
+				Household: req.Household{Children: []req.Child{{BirthYear: 2018}}},
+			},
+			wantCode: http.StatusBadRequest,
+			wantBody: Err{Message: "BirthYear: Invalid birthYear is required in the Buddhist era"},
+		},
+		{
+			name: "given negative disabled dependents to calculate tax should return code 400 and message",
+			ie: req.IncomeExpense{
+				Household: req.Household{DisabledDependents: -1},
+			},
+			wantCode: http.StatusBadRequest,
+			wantBody: Err{Message: "DisabledDependents: Invalid disabledDependents is required 0 or more"},
+		},
 		{
 			name: "given withholding,income than 0 to calculate tax should return code 200",
 			ie: req.IncomeExpense{
-				TotalIncome: 0,
-				Wht:         0,
+				TotalIncome: money.Baht(0),
+				Wht:         money.Baht(0),
 			},
 			wantCode: http.StatusOK,
 		},
@@ -186,79 +383,79 @@ func TestTaxCalculation(t *testing.T) {
 	tt := []struct {
 		name string
 		ie   req.IncomeExpense
-		want float64
+		want money.Money
 	}{
 		{
 			name: "Free tax when income is 0",
 			ie: req.IncomeExpense{
-				TotalIncome: 0.0,
-				Wht:         0.0,
+				TotalIncome: money.Baht(0.0),
+				Wht:         money.Baht(0.0),
 			},
-			want: 0,
+			want: money.Baht(0),
 		},
 		{
 			name: "Free tax when income is 210,000",
 			ie: req.IncomeExpense{
-				TotalIncome: 210000,
-				Wht:         0.0,
+				TotalIncome: money.Baht(210000),
+				Wht:         money.Baht(0.0),
 			},
-			want: 0,
+			want: money.Baht(0),
 		},
 		{
 			name: "tax 0.1 when income is 210,001",
 			ie: req.IncomeExpense{
-				TotalIncome: 210001,
-				Wht:         0.0,
+				TotalIncome: money.Baht(210001),
+				Wht:         money.Baht(0.0),
 			},
-			want: 0.1,
+			want: money.Baht(0.1),
 		},
 		{
 			name: "tax 35,000 when income is 560,000",
 			ie: req.IncomeExpense{
-				TotalIncome: 560000,
-				Wht:         0.0,
+				TotalIncome: money.Baht(560000),
+				Wht:         money.Baht(0.0),
 			},
-			want: 35000,
+			want: money.Baht(35000),
 		},
 		{
 			name: "tax 35,000.1 when income is 560,001",
 			ie: req.IncomeExpense{
-				TotalIncome: 560001,
-				Wht:         0.0,
+				TotalIncome: money.Baht(560001),
+				Wht:         money.Baht(0.0),
 			},
-			want: 35000 + 0.15,
+			want: money.Baht(35000 + 0.15),
 		},
 		{
 			name: "tax 110,000 when income is 1,060,000",
 			ie: req.IncomeExpense{
-				TotalIncome: 1060000,
-				Wht:         0.0,
+				TotalIncome: money.Baht(1060000),
+				Wht:         money.Baht(0.0),
 			},
-			want: 35000 + 75000,
+			want: money.Baht(35000 + 75000),
 		},
 		{
 			name: "tax 110,000.2 when income is 1,060,001",
 			ie: req.IncomeExpense{
-				TotalIncome: 1060001,
-				Wht:         0.0,
+				TotalIncome: money.Baht(1060001),
+				Wht:         money.Baht(0.0),
 			},
-			want: 35000 + 75000 + 0.2,
+			want: money.Baht(35000 + 75000 + 0.2),
 		},
 		{
 			name: "tax 210,000 when income is 2,060,000",
 			ie: req.IncomeExpense{
-				TotalIncome: 2060000,
-				Wht:         0.0,
+				TotalIncome: money.Baht(2060000),
+				Wht:         money.Baht(0.0),
 			},
-			want: 35000 + 75000 + 200000,
+			want: money.Baht(35000 + 75000 + 200000),
 		},
 		{
 			name: "tax 210,000.35 when income is 2,060,001",
 			ie: req.IncomeExpense{
-				TotalIncome: 2060001,
-				Wht:         0.0,
+				TotalIncome: money.Baht(2060001),
+				Wht:         money.Baht(0.0),
 			},
-			want: 35000 + 75000 + 200000 + 0.35,
+			want: money.Baht(35000 + 75000 + 200000 + 0.35),
 		},
 	}
 
@@ -278,7 +475,7 @@ func TestTaxCalculation(t *testing.T) {
 
 			h := New(stubStore)
 
-			var want = resp.Tax{Tax: tCase.want}
+			var want = resp.Tax{TaxYear: 2567, Tax: tCase.want}
 
 			h.Calculation(c)
 			var got resp.Tax
@@ -306,61 +503,69 @@ func TestTaxCalculationWithWht(t *testing.T) {
 		wantTax any
 	}{
 		{
-			name: "tax 19,000, wiht 0,allowance 200000, when income is 500,000",
+			name: "tax 24,600, wiht 0,allowance 200000 capped at 44,000, when income is 500,000",
 			ie: req.IncomeExpense{
-				TotalIncome: 500000.0,
-				Wht:         0.0,
+				TotalIncome: money.Baht(500000.0),
+				Wht:         money.Baht(0.0),
 				Allowances: []req.Allowance{
-					{AllowanceType: "donation", Amount: 200000.0},
+					{AllowanceType: "donation", Amount: money.Baht(200000.0)},
 				},
 			},
-			wantTax: resp.Tax{Tax: 19000.0},
+			wantTax: resp.Tax{TaxYear: 2567, Tax: money.Baht(24600.0), Allowances: []resp.Allowance{{AllowanceType: "donation", Claimed: money.Baht(200000), Allowed: money.Baht(44000), Cap: moneyPtr(44000)}}},
 		},
 		{
-			name: "tax 22,000, wiht 0,allowance 70,000, when income is 500,000",
+			name: "tax 24,600, wiht 0,allowance 70,000 capped at 44,000, when income is 500,000",
 			ie: req.IncomeExpense{
-				TotalIncome: 500000.0,
-				Wht:         0.0,
+				TotalIncome: money.Baht(500000.0),
+				Wht:         money.Baht(0.0),
 				Allowances: []req.Allowance{
-					{AllowanceType: "donation", Amount: 70000.0},
+					{AllowanceType: "donation", Amount: money.Baht(70000.0)},
 				},
 			},
-			wantTax: resp.Tax{Tax: 22000.0},
+			wantTax: resp.Tax{TaxYear: 2567, Tax: money.Baht(24600.0), Allowances: []resp.Allowance{{AllowanceType: "donation", Claimed: money.Baht(70000), Allowed: money.Baht(44000), Cap: moneyPtr(44000)}}},
 		},
 		{
 			name: "tax 22,000, wiht 0,allowance 0, when income is 500,000",
 			ie: req.IncomeExpense{
-				TotalIncome: 500000.0,
-				Wht:         0.0,
+				TotalIncome: money.Baht(500000.0),
+				Wht:         money.Baht(0.0),
 				Allowances: []req.Allowance{
-					{AllowanceType: "donation", Amount: 0},
+					{AllowanceType: "donation", Amount: money.Baht(0)},
 				},
 			},
-			wantTax: resp.Tax{Tax: 29000.0},
+			wantTax: resp.Tax{TaxYear: 2567, Tax: money.Baht(29000.0), Allowances: []resp.Allowance{{AllowanceType: "donation", Claimed: money.Baht(0), Allowed: money.Baht(0), Cap: moneyPtr(44000)}}},
 		},
 		{
 			name: "tax 35,000, wiht 0 when income is 560,000",
 			ie: req.IncomeExpense{
-				TotalIncome: 560000,
-				Wht:         0.0,
+				TotalIncome: money.Baht(560000),
+				Wht:         money.Baht(0.0),
 			},
-			wantTax: resp.Tax{Tax: 35000},
+			wantTax: resp.Tax{TaxYear: 2567, Tax: money.Baht(35000)},
 		},
 		{
 			name: "tax 23,000, wiht 12,000 when income is 560,000",
 			ie: req.IncomeExpense{
-				TotalIncome: 560000,
-				Wht:         12000.0,
+				TotalIncome: money.Baht(560000),
+				Wht:         money.Baht(12000.0),
 			},
-			wantTax: resp.Tax{Tax: 23000},
+			wantTax: resp.Tax{TaxYear: 2567, Tax: money.Baht(23000)},
 		},
 		{
 			name: "tax 0, wiht 40,000 when income is 560,000",
 			ie: req.IncomeExpense{
-				TotalIncome: 560000,
-				Wht:         40000.0,
+				TotalIncome: money.Baht(560000),
+				Wht:         money.Baht(40000.0),
+			},
+			wantTax: resp.TaxWithRefund{Tax: resp.Tax{TaxYear: 2567, Tax: money.Baht(0)}, TaxRefund: money.Baht(5000)},
+		},
+		{
+			name: "tax 0, refund 5,000.30 without fractional tail, wiht 40,000.30 when income is 560,000",
+			ie: req.IncomeExpense{
+				TotalIncome: money.Baht(560000),
+				Wht:         money.Baht(40000.3),
 			},
-			wantTax: resp.TaxWithRefund{Tax: resp.Tax{Tax: 0}, TaxRefund: 5000},
+			wantTax: resp.TaxWithRefund{Tax: resp.Tax{TaxYear: 2567, Tax: money.Baht(0)}, TaxRefund: money.Baht(5000.3)},
 		},
 	}
 
@@ -401,9 +606,11 @@ func TestTaxCalculationWithWht(t *testing.T) {
 
 			if v, ok := got.(resp.Tax); ok {
 				v.TaxLevels = nil
+				v.Rates = resp.Rates{}
 				got = v
 			} else if v, ok := got.(resp.TaxWithRefund); ok {
 				v.TaxLevels = nil
+				v.Rates = resp.Rates{}
 				got = v
 			} else {
 				t.Errorf("expected type %T but got type %T", wantTax, got)
@@ -425,154 +632,157 @@ func TestTaxCalculationToLevel(t *testing.T) {
 		{
 			name: "Free tax when income is 0",
 			ie: req.IncomeExpense{
-				TotalIncome: 0.0,
-				Wht:         0.0,
+				TotalIncome: money.Baht(0.0),
+				Wht:         money.Baht(0.0),
 			},
-			want: resp.Tax{Tax: 0, TaxLevels: []resp.TaxLevel{
-				{Tax: 0, Level: "0-150,000"},
-				{Tax: 0, Level: "150,001-500,000"},
-				{Tax: 0, Level: "500,001-1,000,000"},
-				{Tax: 0, Level: "1,000,001-2,000,000"},
-				{Tax: 0, Level: "2,000,001 ขึ้นไป"},
+			want: resp.Tax{TaxYear: 2567, Tax: money.Baht(0), Rates: resp.Rates{NetIncome: money.Baht(0), UnusedDeduction: moneyPtr(60000), MarginalRate: 0, Headroom: moneyPtr(150000), EffectiveRate: 0, EffectiveRateOnNet: 0}, TaxLevels: []resp.TaxLevel{
+				{Tax: money.Baht(0), Level: "0-150,000", Rate: 0, Taxable: money.Baht(0)},
+				{Tax: money.Baht(0), Level: "150,001-500,000", Rate: 10, Taxable: money.Baht(0)},
+				{Tax: money.Baht(0), Level: "500,001-1,000,000", Rate: 15, Taxable: money.Baht(0)},
+				{Tax: money.Baht(0), Level: "1,000,001-2,000,000", Rate: 20, Taxable: money.Baht(0)},
+				{Tax: money.Baht(0), Level: "2,000,001 ขึ้นไป", Rate: 35, Taxable: money.Baht(0)},
 			},
 			},
 		},
 		{
 			name: "Free tax when income is 210,000",
 			ie: req.IncomeExpense{
-				TotalIncome: 210000,
-				Wht:         0.0,
+				TotalIncome: money.Baht(210000),
+				Wht:         money.Baht(0.0),
 			},
-			want: resp.Tax{Tax: 0, TaxLevels: []resp.TaxLevel{
-				{Tax: 0, Level: "0-150,000"},
-				{Tax: 0, Level: "150,001-500,000"},
-				{Tax: 0, Level: "500,001-1,000,000"},
-				{Tax: 0, Level: "1,000,001-2,000,000"},
-				{Tax: 0, Level: "2,000,001 ขึ้นไป"},
+			want: resp.Tax{TaxYear: 2567, Tax: money.Baht(0), Rates: resp.Rates{NetIncome: money.Baht(150000), MarginalRate: 0, Headroom: moneyPtr(0), EffectiveRate: 0, EffectiveRateOnNet: 0}, TaxLevels: []resp.TaxLevel{
+				{Tax: money.Baht(0), Level: "0-150,000", Rate: 0, Taxable: money.Baht(150000)},
+				{Tax: money.Baht(0), Level: "150,001-500,000", Rate: 10, Taxable: money.Baht(0)},
+				{Tax: money.Baht(0), Level: "500,001-1,000,000", Rate: 15, Taxable: money.Baht(0)},
+				{Tax: money.Baht(0), Level: "1,000,001-2,000,000", Rate: 20, Taxable: money.Baht(0)},
+				{Tax: money.Baht(0), Level: "2,000,001 ขึ้นไป", Rate: 35, Taxable: money.Baht(0)},
 			},
 			},
 		},
 		{
 			name: "tax 0.1 when income is 210,001",
 			ie: req.IncomeExpense{
-				TotalIncome: 210001,
-				Wht:         0.0,
+				TotalIncome: money.Baht(210001),
+				Wht:         money.Baht(0.0),
 			},
-			want: resp.Tax{Tax: 0.1, TaxLevels: []resp.TaxLevel{
-				{Tax: 0, Level: "0-150,000"},
-				{Tax: 0.1, Level: "150,001-500,000"},
-				{Tax: 0, Level: "500,001-1,000,000"},
-				{Tax: 0, Level: "1,000,001-2,000,000"},
-				{Tax: 0, Level: "2,000,001 ขึ้นไป"},
+			want: resp.Tax{TaxYear: 2567, Tax: money.Baht(0.1), Rates: resp.Rates{NetIncome: money.Baht(150001), MarginalRate: 10, Headroom: moneyPtr(349999), EffectiveRate: 0, EffectiveRateOnNet: 0}, TaxLevels: []resp.TaxLevel{
+				{Tax: money.Baht(0), Level: "0-150,000", Rate: 0, Taxable: money.Baht(150000)},
+				{Tax: money.Baht(0.1), Level: "150,001-500,000", Rate: 10, Taxable: money.Baht(1)},
+				{Tax: money.Baht(0), Level: "500,001-1,000,000", Rate: 15, Taxable: money.Baht(0)},
+				{Tax: money.Baht(0), Level: "1,000,001-2,000,000", Rate: 20, Taxable: money.Baht(0)},
+				{Tax: money.Baht(0), Level: "2,000,001 ขึ้นไป", Rate: 35, Taxable: money.Baht(0)},
 			},
 			},
 		},
 		{
 			name: "tax 35,000 when income is 560,000",
 			ie: req.IncomeExpense{
-				TotalIncome: 560000,
-				Wht:         0.0,
+				TotalIncome: money.Baht(560000),
+				Wht:         money.Baht(0.0),
 			},
-			want: resp.Tax{Tax: 35000, TaxLevels: []resp.TaxLevel{
-				{Tax: 0, Level: "0-150,000"},
-				{Tax: 35000, Level: "150,001-500,000"},
-				{Tax: 0, Level: "500,001-1,000,000"},
-				{Tax: 0, Level: "1,000,001-2,000,000"},
-				{Tax: 0, Level: "2,000,001 ขึ้นไป"},
+			want: resp.Tax{TaxYear: 2567, Tax: money.Baht(35000), Rates: resp.Rates{NetIncome: money.Baht(500000), MarginalRate: 10, Headroom: moneyPtr(0), EffectiveRate: 6.25, EffectiveRateOnNet: 7}, TaxLevels: []resp.TaxLevel{
+				{Tax: money.Baht(0), Level: "0-150,000", Rate: 0, Taxable: money.Baht(150000)},
+				{Tax: money.Baht(35000), Level: "150,001-500,000", Rate: 10, Taxable: money.Baht(350000)},
+				{Tax: money.Baht(0), Level: "500,001-1,000,000", Rate: 15, Taxable: money.Baht(0)},
+				{Tax: money.Baht(0), Level: "1,000,001-2,000,000", Rate: 20, Taxable: money.Baht(0)},
+				{Tax: money.Baht(0), Level: "2,000,001 ขึ้นไป", Rate: 35, Taxable: money.Baht(0)},
 			},
 			},
 		},
 		{
 			name: "tax 35,000.1 when income is 560,001",
 			ie: req.IncomeExpense{
-				TotalIncome: 560001,
-				Wht:         0.0,
+				TotalIncome: money.Baht(560001),
+				Wht:         money.Baht(0.0),
 			},
-			want: resp.Tax{Tax: 35000.15, TaxLevels: []resp.TaxLevel{
-				{Tax: 0, Level: "0-150,000"},
-				{Tax: 35000, Level: "150,001-500,000"},
-				{Tax: 0.15, Level: "500,001-1,000,000"},
-				{Tax: 0, Level: "1,000,001-2,000,000"},
-				{Tax: 0, Level: "2,000,001 ขึ้นไป"},
+			want: resp.Tax{TaxYear: 2567, Tax: money.Baht(35000.15), Rates: resp.Rates{NetIncome: money.Baht(500001), MarginalRate: 15, Headroom: moneyPtr(499999), EffectiveRate: 6.25, EffectiveRateOnNet: 7}, TaxLevels: []resp.TaxLevel{
+				{Tax: money.Baht(0), Level: "0-150,000", Rate: 0, Taxable: money.Baht(150000)},
+				{Tax: money.Baht(35000), Level: "150,001-500,000", Rate: 10, Taxable: money.Baht(350000)},
+				{Tax: money.Baht(0.15), Level: "500,001-1,000,000", Rate: 15, Taxable: money.Baht(1)},
+				{Tax: money.Baht(0), Level: "1,000,001-2,000,000", Rate: 20, Taxable: money.Baht(0)},
+				{Tax: money.Baht(0), Level: "2,000,001 ขึ้นไป", Rate: 35, Taxable: money.Baht(0)},
 			},
 			},
 		},
 		{
 			name: "tax 110,000 when income is 1,060,000",
 			ie: req.IncomeExpense{
-				TotalIncome: 1060000,
-				Wht:         0.0,
+				TotalIncome: money.Baht(1060000),
+				Wht:         money.Baht(0.0),
 			},
-			want: resp.Tax{Tax: 110000, TaxLevels: []resp.TaxLevel{
-				{Tax: 0, Level: "0-150,000"},
-				{Tax: 35000, Level: "150,001-500,000"},
-				{Tax: 75000, Level: "500,001-1,000,000"},
-				{Tax: 0, Level: "1,000,001-2,000,000"},
-				{Tax: 0, Level: "2,000,001 ขึ้นไป"},
+			want: resp.Tax{TaxYear: 2567, Tax: money.Baht(110000), Rates: resp.Rates{NetIncome: money.Baht(1000000), MarginalRate: 15, Headroom: moneyPtr(0), EffectiveRate: 10.38, EffectiveRateOnNet: 11}, TaxLevels: []resp.TaxLevel{
+				{Tax: money.Baht(0), Level: "0-150,000", Rate: 0, Taxable: money.Baht(150000)},
+				{Tax: money.Baht(35000), Level: "150,001-500,000", Rate: 10, Taxable: money.Baht(350000)},
+				{Tax: money.Baht(75000), Level: "500,001-1,000,000", Rate: 15, Taxable: money.Baht(500000)},
+				{Tax: money.Baht(0), Level: "1,000,001-2,000,000", Rate: 20, Taxable: money.Baht(0)},
+				{Tax: money.Baht(0), Level: "2,000,001 ขึ้นไป", Rate: 35, Taxable: money.Baht(0)},
 			},
 			},
 		},
 		{
 			name: "tax 110,000.2 when income is 1,060,001",
 			ie: req.IncomeExpense{
-				TotalIncome: 1060001,
-				Wht:         0.0,
+				TotalIncome: money.Baht(1060001),
+				Wht:         money.Baht(0.0),
 			},
-			want: resp.Tax{Tax: 110000.2, TaxLevels: []resp.TaxLevel{
-				{Tax: 0, Level: "0-150,000"},
-				{Tax: 35000, Level: "150,001-500,000"},
-				{Tax: 75000, Level: "500,001-1,000,000"},
-				{Tax: 0.2, Level: "1,000,001-2,000,000"},
-				{Tax: 0, Level: "2,000,001 ขึ้นไป"},
+			want: resp.Tax{TaxYear: 2567, Tax: money.Baht(110000.2), Rates: resp.Rates{NetIncome: money.Baht(1000001), MarginalRate: 20, Headroom: moneyPtr(999999), EffectiveRate: 10.38, EffectiveRateOnNet: 11}, TaxLevels: []resp.TaxLevel{
+				{Tax: money.Baht(0), Level: "0-150,000", Rate: 0, Taxable: money.Baht(150000)},
+				{Tax: money.Baht(35000), Level: "150,001-500,000", Rate: 10, Taxable: money.Baht(350000)},
+				{Tax: money.Baht(75000), Level: "500,001-1,000,000", Rate: 15, Taxable: money.Baht(500000)},
+				{Tax: money.Baht(0.2), Level: "1,000,001-2,000,000", Rate: 20, Taxable: money.Baht(1)},
+				{Tax: money.Baht(0), Level: "2,000,001 ขึ้นไป", Rate: 35, Taxable: money.Baht(0)},
 			},
 			},
 		},
 		{
 			name: "tax 210,000 when income is 2,060,000",
 			ie: req.IncomeExpense{
-				TotalIncome: 2060000,
-				Wht:         0.0,
+				TotalIncome: money.Baht(2060000),
+				Wht:         money.Baht(0.0),
 			},
-			want: resp.Tax{Tax: 310000, TaxLevels: []resp.TaxLevel{
-				{Tax: 0, Level: "0-150,000"},
-				{Tax: 35000, Level: "150,001-500,000"},
-				{Tax: 75000, Level: "500,001-1,000,000"},
-				{Tax: 200000, Level: "1,000,001-2,000,000"},
-				{Tax: 0, Level: "2,000,001 ขึ้นไป"},
+			want: resp.Tax{TaxYear: 2567, Tax: money.Baht(310000), Rates: resp.Rates{NetIncome: money.Baht(2000000), MarginalRate: 20, Headroom: moneyPtr(0), EffectiveRate: 15.05, EffectiveRateOnNet: 15.5}, TaxLevels: []resp.TaxLevel{
+				{Tax: money.Baht(0), Level: "0-150,000", Rate: 0, Taxable: money.Baht(150000)},
+				{Tax: money.Baht(35000), Level: "150,001-500,000", Rate: 10, Taxable: money.Baht(350000)},
+				{Tax: money.Baht(75000), Level: "500,001-1,000,000", Rate: 15, Taxable: money.Baht(500000)},
+				{Tax: money.Baht(200000), Level: "1,000,001-2,000,000", Rate: 20, Taxable: money.Baht(1000000)},
+				{Tax: money.Baht(0), Level: "2,000,001 ขึ้นไป", Rate: 35, Taxable: money.Baht(0)},
 			},
 			},
 		},
 		{
 			name: "tax 210,000.35 when income is 2,060,001",
 			ie: req.IncomeExpense{
-				TotalIncome: 2060001,
-				Wht:         0.0,
+				TotalIncome: money.Baht(2060001),
+				Wht:         money.Baht(0.0),
 			},
-			want: resp.Tax{Tax: 310000.35, TaxLevels: []resp.TaxLevel{
-				{Tax: 0, Level: "0-150,000"},
-				{Tax: 35000, Level: "150,001-500,000"},
-				{Tax: 75000, Level: "500,001-1,000,000"},
-				{Tax: 200000, Level: "1,000,001-2,000,000"},
-				{Tax: 0.35, Level: "2,000,001 ขึ้นไป"},
+			want: resp.Tax{TaxYear: 2567, Tax: money.Baht(310000.35), Rates: resp.Rates{NetIncome: money.Baht(2000001), MarginalRate: 35, EffectiveRate: 15.05, EffectiveRateOnNet: 15.5}, TaxLevels: []resp.TaxLevel{
+				{Tax: money.Baht(0), Level: "0-150,000", Rate: 0, Taxable: money.Baht(150000)},
+				{Tax: money.Baht(35000), Level: "150,001-500,000", Rate: 10, Taxable: money.Baht(350000)},
+				{Tax: money.Baht(75000), Level: "500,001-1,000,000", Rate: 15, Taxable: money.Baht(500000)},
+				{Tax: money.Baht(200000), Level: "1,000,001-2,000,000", Rate: 20, Taxable: money.Baht(1000000)},
+				{Tax: money.Baht(0.35), Level: "2,000,001 ขึ้นไป", Rate: 35, Taxable: money.Baht(1)},
 			},
 			},
 		},
 		{
-			name: "tax 268,000 when income is 2,000,000, donation is 180,000.0, k-receipt is 65,000.0",
+			name: "tax 252,000 when income is 2,000,000, donation is 180,000.0, k-receipt is 65,000.0",
 			ie: req.IncomeExpense{
-				TotalIncome: 2000000,
-				Wht:         0.0,
+				TotalIncome: money.Baht(2000000),
+				Wht:         money.Baht(0.0),
 				Allowances: []req.Allowance{
-					{AllowanceType: "donation", Amount: 180000.0},
-					{AllowanceType: "k-receipt", Amount: 65000.0},
+					{AllowanceType: "donation", Amount: money.Baht(180000.0)},
+					{AllowanceType: "k-receipt", Amount: money.Baht(65000.0)},
 				},
 			},
-			want: resp.Tax{Tax: 268000, TaxLevels: []resp.TaxLevel{
-				{Tax: 0, Level: "0-150,000"},
-				{Tax: 35000, Level: "150,001-500,000"},
-				{Tax: 75000, Level: "500,001-1,000,000"},
-				{Tax: 158000, Level: "1,000,001-2,000,000"},
-				{Tax: 0, Level: "2,000,001 ขึ้นไป"},
+			want: resp.Tax{TaxYear: 2567, Tax: money.Baht(252000), Rates: resp.Rates{NetIncome: money.Baht(1710000), MarginalRate: 20, Headroom: moneyPtr(290000), EffectiveRate: 12.6, EffectiveRateOnNet: 14.74}, Allowances: []resp.Allowance{
+				{AllowanceType: "donation", Claimed: money.Baht(180000), Allowed: money.Baht(180000), Cap: moneyPtr(189000)},
+				{AllowanceType: "k-receipt", Claimed: money.Baht(65000), Allowed: money.Baht(50000)},
+			}, TaxLevels: []resp.TaxLevel{
+				{Tax: money.Baht(0), Level: "0-150,000", Rate: 0, Taxable: money.Baht(150000)},
+				{Tax: money.Baht(35000), Level: "150,001-500,000", Rate: 10, Taxable: money.Baht(350000)},
+				{Tax: money.Baht(75000), Level: "500,001-1,000,000", Rate: 15, Taxable: money.Baht(500000)},
+				{Tax: money.Baht(142000), Level: "1,000,001-2,000,000", Rate: 20, Taxable: money.Baht(710000)},
+				{Tax: money.Baht(0), Level: "2,000,001 ขึ้นไป", Rate: 35, Taxable: money.Baht(0)},
 			},
 			},
 		},
@@ -580,19 +790,22 @@ func TestTaxCalculationToLevel(t *testing.T) {
 		{
 			name: "tax 273,000 when income is 2,000,000, donation is 80,000, k-receipt is 45,000",
 			ie: req.IncomeExpense{
-				TotalIncome: 2000000,
-				Wht:         0.0,
+				TotalIncome: money.Baht(2000000),
+				Wht:         money.Baht(0.0),
 				Allowances: []req.Allowance{
-					{AllowanceType: "donation", Amount: 80000.0},
-					{AllowanceType: "k-receipt", Amount: 45000.0},
+					{AllowanceType: "donation", Amount: money.Baht(80000.0)},
+					{AllowanceType: "k-receipt", Amount: money.Baht(45000.0)},
 				},
 			},
-			want: resp.Tax{Tax: 273000, TaxLevels: []resp.TaxLevel{
-				{Tax: 0, Level: "0-150,000"},
-				{Tax: 35000, Level: "150,001-500,000"},
-				{Tax: 75000, Level: "500,001-1,000,000"},
-				{Tax: 163000, Level: "1,000,001-2,000,000"},
-				{Tax: 0, Level: "2,000,001 ขึ้นไป"},
+			want: resp.Tax{TaxYear: 2567, Tax: money.Baht(273000), Rates: resp.Rates{NetIncome: money.Baht(1815000), MarginalRate: 20, Headroom: moneyPtr(185000), EffectiveRate: 13.65, EffectiveRateOnNet: 15.04}, Allowances: []resp.Allowance{
+				{AllowanceType: "donation", Claimed: money.Baht(80000), Allowed: money.Baht(80000), Cap: moneyPtr(189500)},
+				{AllowanceType: "k-receipt", Claimed: money.Baht(45000), Allowed: money.Baht(45000)},
+			}, TaxLevels: []resp.TaxLevel{
+				{Tax: money.Baht(0), Level: "0-150,000", Rate: 0, Taxable: money.Baht(150000)},
+				{Tax: money.Baht(35000), Level: "150,001-500,000", Rate: 10, Taxable: money.Baht(350000)},
+				{Tax: money.Baht(75000), Level: "500,001-1,000,000", Rate: 15, Taxable: money.Baht(500000)},
+				{Tax: money.Baht(163000), Level: "1,000,001-2,000,000", Rate: 20, Taxable: money.Baht(815000)},
+				{Tax: money.Baht(0), Level: "2,000,001 ขึ้นไป", Rate: 35, Taxable: money.Baht(0)},
 			},
 			},
 		},
@@ -638,21 +851,24 @@ func TestTaxCalculationWithAllowances(t *testing.T) {
 		want resp.Tax
 	}{
 		{
-			name: "tax 268,000 when income is 2,000,000, donation is 180,000.0, k-receipt is 65,000.0",
+			name: "tax 252,000 when income is 2,000,000, donation is 180,000.0, k-receipt is 65,000.0",
 			ie: req.IncomeExpense{
-				TotalIncome: 2000000,
-				Wht:         0.0,
+				TotalIncome: money.Baht(2000000),
+				Wht:         money.Baht(0.0),
 				Allowances: []req.Allowance{
-					{AllowanceType: "donation", Amount: 180000.0},
-					{AllowanceType: "k-receipt", Amount: 65000.0},
+					{AllowanceType: "donation", Amount: money.Baht(180000.0)},
+					{AllowanceType: "k-receipt", Amount: money.Baht(65000.0)},
 				},
 			},
-			want: resp.Tax{Tax: 268000, TaxLevels: []resp.TaxLevel{
-				{Tax: 0, Level: "0-150,000"},
-				{Tax: 35000, Level: "150,001-500,000"},
-				{Tax: 75000, Level: "500,001-1,000,000"},
-				{Tax: 158000, Level: "1,000,001-2,000,000"},
-				{Tax: 0, Level: "2,000,001 ขึ้นไป"},
+			want: resp.Tax{TaxYear: 2567, Tax: money.Baht(252000), Rates: resp.Rates{NetIncome: money.Baht(1710000), MarginalRate: 20, Headroom: moneyPtr(290000), EffectiveRate: 12.6, EffectiveRateOnNet: 14.74}, Allowances: []resp.Allowance{
+				{AllowanceType: "donation", Claimed: money.Baht(180000), Allowed: money.Baht(180000), Cap: moneyPtr(189000)},
+				{AllowanceType: "k-receipt", Claimed: money.Baht(65000), Allowed: money.Baht(50000)},
+			}, TaxLevels: []resp.TaxLevel{
+				{Tax: money.Baht(0), Level: "0-150,000", Rate: 0, Taxable: money.Baht(150000)},
+				{Tax: money.Baht(35000), Level: "150,001-500,000", Rate: 10, Taxable: money.Baht(350000)},
+				{Tax: money.Baht(75000), Level: "500,001-1,000,000", Rate: 15, Taxable: money.Baht(500000)},
+				{Tax: money.Baht(142000), Level: "1,000,001-2,000,000", Rate: 20, Taxable: money.Baht(710000)},
+				{Tax: money.Baht(0), Level: "2,000,001 ขึ้นไป", Rate: 35, Taxable: money.Baht(0)},
 			},
 			},
 		},
@@ -660,19 +876,62 @@ func TestTaxCalculationWithAllowances(t *testing.T) {
 		{
 			name: "tax 273,000 when income is 2,000,000, donation is 80,000, k-receipt is 45,000",
 			ie: req.IncomeExpense{
-				TotalIncome: 2000000,
-				Wht:         0.0,
+				TotalIncome: money.Baht(2000000),
+				Wht:         money.Baht(0.0),
+				Allowances: []req.Allowance{
+					{AllowanceType: "donation", Amount: money.Baht(80000.0)},
+					{AllowanceType: "k-receipt", Amount: money.Baht(45000.0)},
+				},
+			},
+			want: resp.Tax{TaxYear: 2567, Tax: money.Baht(273000), Rates: resp.Rates{NetIncome: money.Baht(1815000), MarginalRate: 20, Headroom: moneyPtr(185000), EffectiveRate: 13.65, EffectiveRateOnNet: 15.04}, Allowances: []resp.Allowance{
+				{AllowanceType: "donation", Claimed: money.Baht(80000), Allowed: money.Baht(80000), Cap: moneyPtr(189500)},
+				{AllowanceType: "k-receipt", Claimed: money.Baht(45000), Allowed: money.Baht(45000)},
+			}, TaxLevels: []resp.TaxLevel{
+				{Tax: money.Baht(0), Level: "0-150,000", Rate: 0, Taxable: money.Baht(150000)},
+				{Tax: money.Baht(35000), Level: "150,001-500,000", Rate: 10, Taxable: money.Baht(350000)},
+				{Tax: money.Baht(75000), Level: "500,001-1,000,000", Rate: 15, Taxable: money.Baht(500000)},
+				{Tax: money.Baht(163000), Level: "1,000,001-2,000,000", Rate: 20, Taxable: money.Baht(815000)},
+				{Tax: money.Baht(0), Level: "2,000,001 ขึ้นไป", Rate: 35, Taxable: money.Baht(0)},
+			},
+			},
+		},
+		{
+			name: "tax 0 when income is 100,000, k-receipt is 50,000 of which 10,000 is unused",
+			ie: req.IncomeExpense{
+				TotalIncome: money.Baht(100000),
+				Allowances: []req.Allowance{
+					{AllowanceType: "k-receipt", Amount: money.Baht(50000)},
+					{AllowanceType: "donation", Amount: money.Baht(10000)},
+				},
+			},
+			want: resp.Tax{TaxYear: 2567, Tax: money.Baht(0), Rates: resp.Rates{NetIncome: money.Baht(0), UnusedDeduction: moneyPtr(10000), MarginalRate: 0, Headroom: moneyPtr(150000)}, Allowances: []resp.Allowance{
+				{AllowanceType: "k-receipt", Claimed: money.Baht(50000), Allowed: money.Baht(50000), Unused: moneyPtr(10000)},
+				{AllowanceType: "donation", Claimed: money.Baht(10000), Allowed: money.Baht(0), Cap: moneyPtr(0)},
+			}, TaxLevels: []resp.TaxLevel{
+				{Tax: money.Baht(0), Level: "0-150,000", Rate: 0, Taxable: money.Baht(0)},
+				{Tax: money.Baht(0), Level: "150,001-500,000", Rate: 10, Taxable: money.Baht(0)},
+				{Tax: money.Baht(0), Level: "500,001-1,000,000", Rate: 15, Taxable: money.Baht(0)},
+				{Tax: money.Baht(0), Level: "1,000,001-2,000,000", Rate: 20, Taxable: money.Baht(0)},
+				{Tax: money.Baht(0), Level: "2,000,001 ขึ้นไป", Rate: 35, Taxable: money.Baht(0)},
+			},
+			},
+		},
+		{
+			name: "tax 0 when income is 50,000 and the personal deduction is partly unused",
+			ie: req.IncomeExpense{
+				TotalIncome: money.Baht(50000),
 				Allowances: []req.Allowance{
-					{AllowanceType: "donation", Amount: 80000.0},
-					{AllowanceType: "k-receipt", Amount: 45000.0},
+					{AllowanceType: "k-receipt", Amount: money.Baht(20000)},
 				},
 			},
-			want: resp.Tax{Tax: 273000, TaxLevels: []resp.TaxLevel{
-				{Tax: 0, Level: "0-150,000"},
-				{Tax: 35000, Level: "150,001-500,000"},
-				{Tax: 75000, Level: "500,001-1,000,000"},
-				{Tax: 163000, Level: "1,000,001-2,000,000"},
-				{Tax: 0, Level: "2,000,001 ขึ้นไป"},
+			want: resp.Tax{TaxYear: 2567, Tax: money.Baht(0), Rates: resp.Rates{NetIncome: money.Baht(0), UnusedDeduction: moneyPtr(30000), MarginalRate: 0, Headroom: moneyPtr(150000)}, Allowances: []resp.Allowance{
+				{AllowanceType: "k-receipt", Claimed: money.Baht(20000), Allowed: money.Baht(20000), Unused: moneyPtr(20000)},
+			}, TaxLevels: []resp.TaxLevel{
+				{Tax: money.Baht(0), Level: "0-150,000", Rate: 0, Taxable: money.Baht(0)},
+				{Tax: money.Baht(0), Level: "150,001-500,000", Rate: 10, Taxable: money.Baht(0)},
+				{Tax: money.Baht(0), Level: "500,001-1,000,000", Rate: 15, Taxable: money.Baht(0)},
+				{Tax: money.Baht(0), Level: "1,000,001-2,000,000", Rate: 20, Taxable: money.Baht(0)},
+				{Tax: money.Baht(0), Level: "2,000,001 ขึ้นไป", Rate: 35, Taxable: money.Baht(0)},
 			},
 			},
 		},
@@ -711,68 +970,1228 @@ func TestTaxCalculationWithAllowances(t *testing.T) {
 	}
 }
 
-func TestTaxCalculationCsv(t *testing.T) {
+func TestTaxCalculationWithIncomes(t *testing.T) {
+	ie := req.IncomeExpense{
+		Incomes: []req.Income{
+			{Category: "40(1)", Amount: money.Baht(600000)},
+			{Category: "40(5)", Amount: money.Baht(120000), ActualExpense: money.Baht(20000)},
+		},
+	}
+	want := resp.Tax{TaxYear: 2567, Tax: money.Baht(41000), Rates: resp.Rates{NetIncome: money.Baht(540000), MarginalRate: 15, Headroom: moneyPtr(460000), EffectiveRate: 5.69, EffectiveRateOnNet: 7.59}, TaxLevels: []resp.TaxLevel{
+		{Tax: money.Baht(0), Level: "0-150,000", Rate: 0, Taxable: money.Baht(150000)},
+		{Tax: money.Baht(35000), Level: "150,001-500,000", Rate: 10, Taxable: money.Baht(350000)},
+		{Tax: money.Baht(6000), Level: "500,001-1,000,000", Rate: 15, Taxable: money.Baht(40000)},
+		{Tax: money.Baht(0), Level: "1,000,001-2,000,000", Rate: 20, Taxable: money.Baht(0)},
+		{Tax: money.Baht(0), Level: "2,000,001 ขึ้นไป", Rate: 35, Taxable: money.Baht(0)},
+	}, Incomes: []resp.Income{
+		{Category: "40(1)", Amount: money.Baht(600000), Expense: money.Baht(100000), Net: money.Baht(500000)},
+		{Category: "40(5)", Amount: money.Baht(120000), Expense: money.Baht(20000), Net: money.Baht(100000)},
+	}, TaxMethod: "progressive", TaxMethods: []resp.TaxMethod{
+		{Method: "progressive", Tax: money.Baht(41000)},
+		{Method: "gross-income", Tax: money.Baht(600)},
+	}}
+
+	bytesObj, _ := json.Marshal(ie)
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(string(bytesObj)))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+
+	e := echo.New()
+	c := e.NewContext(req, rec)
+	c.SetPath("/tax/calculations")
+
+	h := New(stubStore)
+
+	h.Calculation(c)
+	var got resp.Tax
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Errorf("unable to unmarshal json: %v", err)
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v but got %v", want, got)
+	}
+}
+
+func TestTaxCalculationWithHousehold(t *testing.T) {
+	ie := req.IncomeExpense{
+		TotalIncome: money.Baht(1000000),
+		Household: req.Household{
+			Spouse:             &req.Spouse{HasIncome: false},
+			Children:           []req.Child{{BirthYear: 2560}, {BirthYear: 2562}},
+			Parents:            []req.Parent{{Age: 65, Income: money.Baht(12000)}},
+			DisabledDependents: 1,
+		},
+	}
+	want := resp.Tax{TaxYear: 2567, Tax: money.Baht(65000), Rates: resp.Rates{NetIncome: money.Baht(700000), MarginalRate: 15, Headroom: moneyPtr(300000), EffectiveRate: 6.5, EffectiveRateOnNet: 9.29}, TaxLevels: []resp.TaxLevel{
+		{Tax: money.Baht(0), Level: "0-150,000", Rate: 0, Taxable: money.Baht(150000)},
+		{Tax: money.Baht(35000), Level: "150,001-500,000", Rate: 10, Taxable: money.Baht(350000)},
+		{Tax: money.Baht(30000), Level: "500,001-1,000,000", Rate: 15, Taxable: money.Baht(200000)},
+		{Tax: money.Baht(0), Level: "1,000,001-2,000,000", Rate: 20, Taxable: money.Baht(0)},
+		{Tax: money.Baht(0), Level: "2,000,001 ขึ้นไป", Rate: 35, Taxable: money.Baht(0)},
+	}, Allowances: []resp.Allowance{
+		{AllowanceType: "spouse", Claimed: money.Baht(60000), Allowed: money.Baht(60000)},
+		{AllowanceType: "child", Claimed: money.Baht(90000), Allowed: money.Baht(90000)},
+		{AllowanceType: "parent", Claimed: money.Baht(30000), Allowed: money.Baht(30000)},
+		{AllowanceType: "disability", Claimed: money.Baht(60000), Allowed: money.Baht(60000)},
+	}}
+
+	bytesObj, _ := json.Marshal(ie)
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(string(bytesObj)))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+
+	e := echo.New()
+	c := e.NewContext(req, rec)
+	c.SetPath("/tax/calculations")
+
+	h := New(stubStore)
+
+	h.Calculation(c)
+	var got resp.Tax
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Errorf("unable to unmarshal json: %v", err)
+	}
 
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v but got %v", want, got)
+	}
+}
+
+func TestTaxCalculationHousehold(t *testing.T) {
 	tt := []struct {
-		name    string
-		csvPath string
-		csvName string
-		want    resp.Taxes
+		name            string
+		hie             req.HouseholdIncomeExpense
+		wantCode        int
+		wantRecommended string
+		wantTaxes       []money.Money
 	}{
 		{
-			name:    "calculate tax is correct when attach correct CSV file",
-			csvPath: "./csv_src/tax_csv.csv",
-			csvName: "tax.csv",
-			want: resp.Taxes{
-				Taxes: []resp.TaxWithIncome{
-					{TotalIncome: 500000, Tax: 29000, TaxRefund: 0},
-					{TotalIncome: 600000, Tax: 0, TaxRefund: 2000},
-					{TotalIncome: 750000, Tax: 11250, TaxRefund: 0},
-				},
+			name: "spouse without income should recommend joint filing",
+			hie: req.HouseholdIncomeExpense{
+				Taxpayer: req.IncomeExpense{TotalIncome: money.Baht(1000000)},
+			},
+			wantCode:        http.StatusOK,
+			wantRecommended: "joint",
+			wantTaxes:       []money.Money{money.Baht(101000), money.Baht(92000)},
+		},
+		{
+			name: "both with the same income should recommend separate filing",
+			hie: req.HouseholdIncomeExpense{
+				Taxpayer: req.IncomeExpense{TotalIncome: money.Baht(1000000)},
+				Spouse:   req.IncomeExpense{TotalIncome: money.Baht(1000000)},
+			},
+			wantCode:        http.StatusOK,
+			wantRecommended: "separate",
+			wantTaxes:       []money.Money{money.Baht(202000), money.Baht(286000)},
+		},
+		{
+			name: "given different tax years should return code 400",
+			hie: req.HouseholdIncomeExpense{
+				Taxpayer: req.IncomeExpense{TaxYear: 2566},
+				Spouse:   req.IncomeExpense{TaxYear: 2567},
+			},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name: "given invalid spouse parent should return code 400",
+			hie: req.HouseholdIncomeExpense{
+				Spouse: req.IncomeExpense{Household: req.Household{Parents: []req.Parent{{Age: 50}}}},
 			},
+			wantCode: http.StatusBadRequest,
 		},
 	}
 
 	for _, tCase := range tt {
-
 		t.Run(tCase.name, func(t *testing.T) {
+			bytesObj, _ := json.Marshal(tCase.hie)
 
-			body := new(bytes.Buffer)
-			writer := multipart.NewWriter(body)
-			part, _ := writer.CreateFormFile("taxFile", tCase.csvName)
-			file, err := os.Open(tCase.csvPath)
-			if err != nil {
-				t.Fatal(err)
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(string(bytesObj)))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+
+			e := echo.New()
+			c := e.NewContext(req, rec)
+			c.SetPath("/tax/calculations/household")
+
+			h := New(stubStore)
+
+			h.CalculationHousehold(c)
+			if rec.Code != tCase.wantCode {
+				t.Fatalf("expected code %v but got code %v", tCase.wantCode, rec.Code)
 			}
-			defer file.Close()
-			if _, err := io.Copy(part, file); err != nil {
-				t.Fatal(err)
+			if tCase.wantCode != http.StatusOK {
+				return
 			}
-			writer.Close()
 
-			req := httptest.NewRequest(http.MethodPost, "/tax/calculations/upload-csv", body)
-			req.Header.Set(echo.HeaderContentType, writer.FormDataContentType())
+			var got resp.HouseholdTax
+			if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+				t.Errorf("unable to unmarshal json: %v", err)
+			}
+			var gotTaxes []money.Money
+			for _, opt := range got.Options {
+				gotTaxes = append(gotTaxes, opt.Tax)
+			}
+			if got.Recommended != tCase.wantRecommended {
+				t.Errorf("expected recommended %v but got %v", tCase.wantRecommended, got.Recommended)
+			}
+			if !reflect.DeepEqual(gotTaxes, tCase.wantTaxes) {
+				t.Errorf("expected %v but got %v", tCase.wantTaxes, gotTaxes)
+			}
+		})
+	}
+}
+
+func TestTaxCalculationGrossUp(t *testing.T) {
+	tt := []struct {
+		name     string
+		g        req.GrossUp
+		wantCode int
+		want     any
+	}{
+		{
+			name:     "net income 481,000 should need salary 500,000",
+			g:        req.GrossUp{NetIncome: money.Baht(481000)},
+			wantCode: http.StatusOK,
+			want: resp.GrossUp{TotalIncome: money.Baht(500000), Tax: resp.Tax{TaxYear: 2567, Tax: money.Baht(19000), Rates: resp.Rates{NetIncome: money.Baht(340000), MarginalRate: 10, Headroom: moneyPtr(160000), EffectiveRate: 3.8, EffectiveRateOnNet: 5.59}, Incomes: []resp.Income{
+				{Category: "40(1)", Amount: money.Baht(500000), Expense: money.Baht(100000), Net: money.Baht(400000)},
+			}, TaxLevels: []resp.TaxLevel{
+				{Tax: money.Baht(0), Level: "0-150,000", Rate: 0, Taxable: money.Baht(150000)},
+				{Tax: money.Baht(19000), Level: "150,001-500,000", Rate: 10, Taxable: money.Baht(190000)},
+				{Tax: money.Baht(0), Level: "500,001-1,000,000", Rate: 15, Taxable: money.Baht(0)},
+				{Tax: money.Baht(0), Level: "1,000,001-2,000,000", Rate: 20, Taxable: money.Baht(0)},
+				{Tax: money.Baht(0), Level: "2,000,001 ขึ้นไป", Rate: 35, Taxable: money.Baht(0)},
+			}}},
+		},
+		{
+			name:     "given negative net income should return code 400 and message",
+			g:        req.GrossUp{NetIncome: money.Baht(-1)},
+			wantCode: http.StatusBadRequest,
+			want:     Err{Message: "NetIncome: Invalid netIncome is required 0.0 or more"},
+		},
+		{
+			name:     "given unknown category should return code 400 and message",
+			g:        req.GrossUp{NetIncome: money.Baht(100000), Category: "40(9)"},
+			wantCode: http.StatusBadRequest,
+			want:     Err{Message: "Income category must be 40(1) to 40(8)."},
+		},
+	}
+
+	for _, tCase := range tt {
+		t.Run(tCase.name, func(t *testing.T) {
+			bytesObj, _ := json.Marshal(tCase.g)
+
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(string(bytesObj)))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 			rec := httptest.NewRecorder()
 
 			e := echo.New()
 			c := e.NewContext(req, rec)
-			c.SetPath("/tax/calculations/upload-csv")
+			c.SetPath("/tax/calculations/gross-up")
 
 			h := New(stubStore)
 
-			var want = tCase.want
+			h.CalculationGrossUp(c)
+			if rec.Code != tCase.wantCode {
+				t.Errorf("expected code %v but got code %v", tCase.wantCode, rec.Code)
+			}
 
-			h.CalculationCSV(c)
-			var got resp.Taxes
-			gotJson := rec.Body.Bytes()
-			if err := json.Unmarshal(gotJson, &got); err != nil {
-				t.Errorf("unable to unmarshal json: %v", err)
+			var got any
+			if tCase.wantCode == http.StatusOK {
+				var g resp.GrossUp
+				if err := json.Unmarshal(rec.Body.Bytes(), &g); err != nil {
+					t.Errorf("unable to unmarshal json: %v", err)
+				}
+				got = g
+			} else {
+				var e Err
+				if err := json.Unmarshal(rec.Body.Bytes(), &e); err != nil {
+					t.Errorf("unable to unmarshal json: %v", err)
+				}
+				got = e
 			}
+			if !reflect.DeepEqual(got, tCase.want) {
+				t.Errorf("expected %v but got %v", tCase.want, got)
+			}
+		})
+	}
+}
 
-			if !reflect.DeepEqual(got, want) {
-				t.Errorf("expected %v but got %v", want, got)
+func TestTaxCalculationScenarios(t *testing.T) {
+	base := req.IncomeExpense{
+		TotalIncome: money.Baht(1000000),
+		Allowances:  []req.Allowance{{AllowanceType: "k-receipt", Amount: money.Baht(20000)}},
+	}
+	tt := []struct {
+		name     string
+		sc       req.Scenarios
+		wantCode int
+		want     any
+	}{
+		{
+			name: "scenarios should be compared with the base",
+			sc: req.Scenarios{Base: base, Scenarios: []req.Scenario{
+				{Name: "k-receipt 50,000", Allowances: []req.Allowance{{AllowanceType: "k-receipt", Amount: money.Baht(50000)}}},
+				{Name: "rmf 100,000", Allowances: []req.Allowance{{AllowanceType: "rmf", Amount: money.Baht(100000)}}},
+			}},
+			wantCode: http.StatusOK,
+			want: resp.Scenarios{
+				Base: resp.ScenarioTax{Name: "base", TotalIncome: money.Baht(1000000), NetIncome: money.Baht(920000), Tax: money.Baht(98000), MarginalRate: 15, EffectiveRate: 9.8},
+				Scenarios: []resp.ScenarioTax{
+					{Name: "k-receipt 50,000", TotalIncome: money.Baht(1000000), NetIncome: money.Baht(890000), Tax: money.Baht(93500), MarginalRate: 15, EffectiveRate: 9.35, TaxDelta: money.Baht(-4500), Contribution: money.Baht(30000), SavingPerBaht: 0.15},
+					{Name: "rmf 100,000", TotalIncome: money.Baht(1000000), NetIncome: money.Baht(820000), Tax: money.Baht(83000), MarginalRate: 15, EffectiveRate: 8.3, TaxDelta: money.Baht(-15000), Contribution: money.Baht(100000), SavingPerBaht: 0.15},
+				},
+				SettingsVersion: 2,
+			},
+		},
+		{
+			name:     "error when no scenario is given",
+			sc:       req.Scenarios{Base: base},
+			wantCode: http.StatusBadRequest,
+			want:     Err{Message: "Scenarios: Invalid scenarios is required at least 1"},
+		},
+		{
+			name: "error when a scenario has the same name",
+			sc: req.Scenarios{Base: base, Scenarios: []req.Scenario{
+				{Name: "rmf"},
+				{Name: "rmf"},
+			}},
+			wantCode: http.StatusBadRequest,
+			want:     Err{Message: "Scenario name 'rmf' is duplicated."},
+		},
+	}
+
+	for _, tCase := range tt {
+		t.Run(tCase.name, func(t *testing.T) {
+			bytesObj, _ := json.Marshal(tCase.sc)
+
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(string(bytesObj)))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+
+			e := echo.New()
+			c := e.NewContext(req, rec)
+			c.SetPath("/tax/scenarios")
+
+			store := stubStore
+			store.version = 2
+			h := New(store)
+
+			h.CalculationScenarios(c)
+			if rec.Code != tCase.wantCode {
+				t.Errorf("expected status %v but got %v", tCase.wantCode, rec.Code)
 			}
 
-		})
+			var got any
+			if tCase.wantCode == http.StatusOK {
+				var s resp.Scenarios
+				if err := json.Unmarshal(rec.Body.Bytes(), &s); err != nil {
+					t.Errorf("unable to unmarshal json: %v", err)
+				}
+				got = s
+			} else {
+				var e Err
+				if err := json.Unmarshal(rec.Body.Bytes(), &e); err != nil {
+					t.Errorf("unable to unmarshal json: %v", err)
+				}
+				got = e
+			}
+			if !reflect.DeepEqual(got, tCase.want) {
+				t.Errorf("expected %v but got %v", tCase.want, got)
+			}
+		})
+	}
+}
+
+func TestTaxCalculationOptimize(t *testing.T) {
+	tt := []struct {
+		name            string
+		o               req.Optimize
+		wantCode        int
+		wantAllocations []resp.Allocation
+		wantSaving      money.Money
+		wantErr         Err
+	}{
+		{
+			name:            "budget should be allocated to the instruments",
+			o:               req.Optimize{IncomeExpense: req.IncomeExpense{TotalIncome: money.Baht(1000000)}, Budget: money.Baht(400000), Instruments: []string{"rmf", "ssf"}},
+			wantCode:        http.StatusOK,
+			wantAllocations: []resp.Allocation{{AllowanceType: "rmf", Amount: money.Baht(300000)}, {AllowanceType: "ssf", Amount: money.Baht(100000)}},
+			wantSaving:      money.Baht(60000),
+		},
+		{
+			name:     "error when budget is negative",
+			o:        req.Optimize{IncomeExpense: req.IncomeExpense{TotalIncome: money.Baht(1000000)}, Budget: money.Baht(-1)},
+			wantCode: http.StatusBadRequest,
+			wantErr:  Err{Message: "Budget: Invalid budget is required 0.0 or more"},
+		},
+		{
+			name:     "error when an instrument is not supported",
+			o:        req.Optimize{IncomeExpense: req.IncomeExpense{TotalIncome: money.Baht(1000000)}, Budget: money.Baht(1000), Instruments: []string{"gold"}},
+			wantCode: http.StatusBadRequest,
+			wantErr:  Err{Message: "AllowanceType 'gold' is not supported."},
+		},
+	}
+
+	for _, tCase := range tt {
+		t.Run(tCase.name, func(t *testing.T) {
+			bytesObj, _ := json.Marshal(tCase.o)
+
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(string(bytesObj)))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+
+			e := echo.New()
+			c := e.NewContext(req, rec)
+			c.SetPath("/tax/optimize")
+
+			h := New(stubStore)
+
+			h.CalculationOptimize(c)
+			if rec.Code != tCase.wantCode {
+				t.Fatalf("expected status %v but got %v", tCase.wantCode, rec.Code)
+			}
+
+			if tCase.wantCode != http.StatusOK {
+				var got Err
+				if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+					t.Errorf("unable to unmarshal json: %v", err)
+				}
+				if got != tCase.wantErr {
+					t.Errorf("expected %v but got %v", tCase.wantErr, got)
+				}
+				return
+			}
+
+			var got resp.Optimize
+			if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+				t.Errorf("unable to unmarshal json: %v", err)
+			}
+			if !reflect.DeepEqual(got.Allocations, tCase.wantAllocations) {
+				t.Errorf("expected %v but got %v", tCase.wantAllocations, got.Allocations)
+			}
+			if got.TaxSaving != tCase.wantSaving || got.Before.Tax.Sub(got.After.Tax) != tCase.wantSaving {
+				t.Errorf("expected saving %v but got %v", tCase.wantSaving, got.TaxSaving)
+			}
+		})
+	}
+}
+
+func TestTaxCalculationPayroll(t *testing.T) {
+	tt := []struct {
+		name     string
+		p        req.Payroll
+		wantCode int
+		want     any
+	}{
+		{
+			name:     "salary 50,000 with bonus 100,000 in June should withhold 14,416.66 in June",
+			p:        req.Payroll{Month: 6, Salary: money.Baht(50000), Bonuses: []req.Bonus{{Month: 6, Amount: money.Baht(100000)}}},
+			wantCode: http.StatusOK,
+			want:     resp.Payroll{TaxYear: 2567, Month: 6, Wht: money.Baht(14416.66), AnnualTax: money.Baht(41000), SettingsVersion: 2},
+		},
+		{
+			name:     "given month 0 should return code 400 and message",
+			p:        req.Payroll{Salary: money.Baht(50000)},
+			wantCode: http.StatusBadRequest,
+			want:     Err{Message: "Month: Invalid month is required 1 to 12"},
+		},
+		{
+			name:     "given bonus in month 13 should return code 400 and message",
+			p:        req.Payroll{Month: 1, Bonuses: []req.Bonus{{Month: 13}}},
+			wantCode: http.StatusBadRequest,
+			want:     Err{Message: "Month: Invalid month is required 1 to 12"},
+		},
+	}
+
+	for _, tCase := range tt {
+		t.Run(tCase.name, func(t *testing.T) {
+			bytesObj, _ := json.Marshal(tCase.p)
+
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(string(bytesObj)))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+
+			e := echo.New()
+			c := e.NewContext(req, rec)
+			c.SetPath("/tax/calculations/payroll")
+
+			store := stubStore
+			store.version = 2
+			h := New(store)
+
+			h.CalculationPayroll(c)
+			if rec.Code != tCase.wantCode {
+				t.Errorf("expected code %v but got code %v", tCase.wantCode, rec.Code)
+			}
+
+			var got any
+			if tCase.wantCode == http.StatusOK {
+				var p resp.Payroll
+				if err := json.Unmarshal(rec.Body.Bytes(), &p); err != nil {
+					t.Errorf("unable to unmarshal json: %v", err)
+				}
+				if len(p.Schedule) != 12 {
+					t.Errorf("expected 12 months schedule but got %d", len(p.Schedule))
+				}
+				p.Schedule = nil
+				got = p
+			} else {
+				var e Err
+				if err := json.Unmarshal(rec.Body.Bytes(), &e); err != nil {
+					t.Errorf("unable to unmarshal json: %v", err)
+				}
+				got = e
+			}
+			if !reflect.DeepEqual(got, tCase.want) {
+				t.Errorf("expected %v but got %v", tCase.want, got)
+			}
+		})
+	}
+}
+
+func TestTaxCalculationHalfYear(t *testing.T) {
+	ie := req.IncomeExpense{
+		Period: "half-year",
+		Incomes: []req.Income{
+			{Category: "40(1)", Amount: money.Baht(300000)},
+			{Category: "40(8)", Amount: money.Baht(600000)},
+		},
+	}
+	want := resp.Tax{TaxYear: 2567, Period: "half-year", Tax: money.Baht(6000), Rates: resp.Rates{NetIncome: money.Baht(210000), MarginalRate: 10, Headroom: moneyPtr(290000), EffectiveRate: 1, EffectiveRateOnNet: 2.86}, TaxLevels: []resp.TaxLevel{
+		{Tax: money.Baht(0), Level: "0-150,000", Rate: 0, Taxable: money.Baht(150000)},
+		{Tax: money.Baht(6000), Level: "150,001-500,000", Rate: 10, Taxable: money.Baht(60000)},
+		{Tax: money.Baht(0), Level: "500,001-1,000,000", Rate: 15, Taxable: money.Baht(0)},
+		{Tax: money.Baht(0), Level: "1,000,001-2,000,000", Rate: 20, Taxable: money.Baht(0)},
+		{Tax: money.Baht(0), Level: "2,000,001 ขึ้นไป", Rate: 35, Taxable: money.Baht(0)},
+	}, Incomes: []resp.Income{
+		{Category: "40(8)", Amount: money.Baht(600000), Expense: money.Baht(360000), Net: money.Baht(240000)},
+	}, TaxMethod: "progressive", TaxMethods: []resp.TaxMethod{
+		{Method: "progressive", Tax: money.Baht(6000)},
+		{Method: "gross-income", Tax: money.Baht(3000)},
+	}}
+
+	bytesObj, _ := json.Marshal(ie)
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(string(bytesObj)))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+
+	e := echo.New()
+	c := e.NewContext(req, rec)
+	c.SetPath("/tax/calculations")
+
+	h := New(stubStore)
+
+	h.Calculation(c)
+	var got resp.Tax
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Errorf("unable to unmarshal json: %v", err)
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v but got %v", want, got)
+	}
+}
+
+func TestTaxCalculationLateFiling(t *testing.T) {
+	ie := req.IncomeExpense{
+		TotalIncome: money.Baht(500000),
+		FilingDate:  "2025-04-10",
+		PaymentDate: "2025-06-15",
+	}
+	want := resp.Late{DueDate: "2025-03-31", Surcharge: moneyPtr(1305), Penalty: moneyPtr(1000), TotalPayable: moneyPtr(31305)}
+
+	bytesObj, _ := json.Marshal(ie)
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(string(bytesObj)))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+
+	e := echo.New()
+	c := e.NewContext(req, rec)
+	c.SetPath("/tax/calculations")
+
+	h := New(stubStore)
+
+	h.Calculation(c)
+	var got resp.Tax
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Errorf("unable to unmarshal json: %v", err)
+	}
+
+	if got.Tax != money.Baht(29000) {
+		t.Errorf("expected tax %v but got %v", money.Baht(29000), got.Tax)
+	}
+	if !reflect.DeepEqual(got.Late, want) {
+		t.Errorf("expected %v but got %v", want, got.Late)
+	}
+}
+
+func TestTaxCalculationWithCredits(t *testing.T) {
+	ie := req.IncomeExpense{
+		TotalIncome:      money.Baht(500000),
+		Dividends:        []req.Dividend{{Amount: money.Baht(100000), CorporateRate: 20, Wht: money.Baht(10000)}},
+		IncludeDividends: true,
+		Incomes:          []req.Income{{Category: "40(8)", Amount: money.Baht(100000), ForeignTax: money.Baht(15000)}},
+	}
+	want := resp.Credits{DividendWht: moneyPtr(10000), DividendCredit: moneyPtr(25000), ForeignTaxCredit: moneyPtr(7000)}
+
+	bytesObj, _ := json.Marshal(ie)
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(string(bytesObj)))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+
+	e := echo.New()
+	c := e.NewContext(req, rec)
+	c.SetPath("/tax/calculations")
+
+	h := New(stubStore)
+
+	h.Calculation(c)
+	var got resp.Tax
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Errorf("unable to unmarshal json: %v", err)
+	}
+
+	if got.Tax != money.Baht(8750) {
+		t.Errorf("expected tax %v but got %v", money.Baht(8750), got.Tax)
+	}
+	if !reflect.DeepEqual(got.Credits, want) {
+		t.Errorf("expected %v but got %v", want, got.Credits)
+	}
+}
+
+func TestTaxCalculationFinalTax(t *testing.T) {
+	tt := []struct {
+		name          string
+		query         string
+		wantCode      int
+		wantElections *resp.Elections
+	}{
+		{
+			name:     "final tax summary without elections when not asked to compare",
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "both elections with the cheaper recommended when compareElections is true",
+			query:    "?compareElections=true",
+			wantCode: http.StatusOK,
+			wantElections: &resp.Elections{
+				Recommended: "annual-return",
+				Options: []resp.Election{
+					{Election: "final-tax", Tax: money.Baht(29000), FinalTax: money.Baht(15000), Total: money.Baht(44000)},
+					{Election: "annual-return", Tax: money.Baht(26000), Total: money.Baht(41000)},
+				},
+			},
+		},
+		{
+			name:     "error when compareElections is not a boolean",
+			query:    "?compareElections=maybe",
+			wantCode: http.StatusBadRequest,
+		},
+	}
+	wantFinalTax := &resp.FinalTax{
+		Incomes: []resp.FinalTaxIncome{{Category: "40(4)", Amount: money.Baht(100000), Wht: money.Baht(15000)}},
+		Tax:     money.Baht(15000),
+	}
+
+	for _, tCase := range tt {
+		t.Run(tCase.name, func(t *testing.T) {
+			ie := req.IncomeExpense{
+				TotalIncome: money.Baht(500000),
+				Incomes:     []req.Income{{Category: "40(4)", Amount: money.Baht(100000), Wht: money.Baht(15000), ElectFinalTax: true}},
+			}
+			bytesObj, _ := json.Marshal(ie)
+
+			req := httptest.NewRequest(http.MethodPost, "/tax/calculations"+tCase.query, strings.NewReader(string(bytesObj)))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+
+			e := echo.New()
+			c := e.NewContext(req, rec)
+
+			h := New(stubStore)
+
+			h.Calculation(c)
+			if rec.Code != tCase.wantCode {
+				t.Fatalf("expected status %v but got %v", tCase.wantCode, rec.Code)
+			}
+			if tCase.wantCode != http.StatusOK {
+				return
+			}
+			var got resp.Tax
+			if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+				t.Errorf("unable to unmarshal json: %v", err)
+			}
+			if got.Tax != money.Baht(29000) {
+				t.Errorf("expected tax %v but got %v", money.Baht(29000), got.Tax)
+			}
+			if !reflect.DeepEqual(got.FinalTax, wantFinalTax) {
+				t.Errorf("expected %v but got %v", wantFinalTax, got.FinalTax)
+			}
+			if !reflect.DeepEqual(got.Elections, tCase.wantElections) {
+				t.Errorf("expected %v but got %v", tCase.wantElections, got.Elections)
+			}
+		})
+	}
+}
+
+func TestTaxCalculationWithLumpSum(t *testing.T) {
+	ie := req.IncomeExpense{
+		TotalIncome: money.Baht(500000),
+		LumpSum:     &req.LumpSum{Amount: money.Baht(1000000), YearsOfService: 10, Wht: money.Baht(50000)},
+	}
+	want := &resp.LumpSum{
+		Amount:           money.Baht(1000000),
+		YearsOfService:   10,
+		ServiceDeduction: money.Baht(70000),
+		HalfDeduction:    money.Baht(465000),
+		NetIncome:        money.Baht(465000),
+		Tax:              money.Baht(31500),
+		Wht:              money.Baht(50000),
+		TaxLevels: []resp.TaxLevel{
+			{Level: "0-150,000", Rate: 0, Taxable: money.Baht(150000), Tax: money.Baht(0)},
+			{Level: "150,001-500,000", Rate: 10, Taxable: money.Baht(315000), Tax: money.Baht(31500)},
+			{Level: "500,001-1,000,000", Rate: 15, Tax: money.Baht(0)},
+			{Level: "1,000,001-2,000,000", Rate: 20, Tax: money.Baht(0)},
+			{Level: "2,000,001 ขึ้นไป", Rate: 35, Tax: money.Baht(0)},
+		},
+	}
+
+	bytesObj, _ := json.Marshal(ie)
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(string(bytesObj)))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+
+	e := echo.New()
+	c := e.NewContext(req, rec)
+	c.SetPath("/tax/calculations")
+
+	h := New(stubStore)
+
+	h.Calculation(c)
+	var got resp.Tax
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Errorf("unable to unmarshal json: %v", err)
+	}
+
+	if got.Tax != money.Baht(10500) {
+		t.Errorf("expected tax %v but got %v", money.Baht(10500), got.Tax)
+	}
+	if !reflect.DeepEqual(got.LumpSum, want) {
+		t.Errorf("expected %v but got %v", want, got.LumpSum)
+	}
+}
+
+func TestTaxCalculationSave(t *testing.T) {
+	store := stubStore
+	store.calculations = map[string]repo.Calculation{}
+	h := New(store)
+
+	ie := req.IncomeExpense{TotalIncome: money.Baht(500000), Wht: money.Baht(10000)}
+	bytesObj, _ := json.Marshal(ie)
+
+	saveReq := httptest.NewRequest(http.MethodPost, "/tax/calculations?save=true", strings.NewReader(string(bytesObj)))
+	saveReq.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+
+	e := echo.New()
+	c := e.NewContext(saveReq, rec)
+
+	h.Calculation(c)
+	var got resp.Tax
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Errorf("unable to unmarshal json: %v", err)
+	}
+	if got.CalculationID != calculationID(1) {
+		t.Fatalf("expected calculation id %v but got %v", calculationID(1), got.CalculationID)
+	}
+
+	tt := []struct {
+		name     string
+		id       string
+		wantCode int
+	}{
+		{
+			name:     "saved calculation should return code 200",
+			id:       calculationID(1),
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "calculation not saved should return code 404",
+			id:       calculationID(2),
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "sequential id should return code 400",
+			id:       "1",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "id not a UUID should return code 400",
+			id:       "abc",
+			wantCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tCase := range tt {
+		t.Run(tCase.name, func(t *testing.T) {
+			getReq := httptest.NewRequest(http.MethodGet, "/", nil)
+			rec := httptest.NewRecorder()
+
+			e := echo.New()
+			c := e.NewContext(getReq, rec)
+			c.SetPath("/tax/calculations/:id")
+			c.SetParamNames("id")
+			c.SetParamValues(tCase.id)
+
+			h.SavedCalculation(c)
+			if rec.Code != tCase.wantCode {
+				t.Fatalf("expected status %v but got %v", tCase.wantCode, rec.Code)
+			}
+			if tCase.wantCode != http.StatusOK {
+				return
+			}
+
+			var calc resp.Calculation
+			if err := json.Unmarshal(rec.Body.Bytes(), &calc); err != nil {
+				t.Errorf("unable to unmarshal json: %v", err)
+			}
+			var gotInput req.IncomeExpense
+			var gotSetting resp.CalculationSetting
+			var gotOutput resp.TaxWithRefund
+			json.Unmarshal(calc.Input, &gotInput)
+			json.Unmarshal(calc.Setting, &gotSetting)
+			json.Unmarshal(calc.Output, &gotOutput)
+			if !reflect.DeepEqual(gotInput, ie) {
+				t.Errorf("expected %v but got %v", ie, gotInput)
+			}
+			if gotSetting.PersonalDeduction != money.Baht(60000) || gotSetting.KReceipt != money.Baht(50000) || len(gotSetting.TaxBrackets.TaxBrackets) != 5 {
+				t.Errorf("expected setting of the stub store but got %v", gotSetting)
+			}
+			if gotOutput.Tax.Tax != money.Baht(19000) || gotOutput.Tax.CalculationID != "" {
+				t.Errorf("expected tax %v without id but got %v", money.Baht(19000), gotOutput.Tax)
+			}
+		})
+	}
+}
+
+func TestTaxCalculationSettingsVersion(t *testing.T) {
+	store := stubStore
+	store.version = 2
+	store.versions = map[int64]deduction.Deduction{
+		1: {Personal: money.Baht(100000), MaxKReceipt: money.Baht(50000)},
+		4: {Personal: money.Baht(60000), MaxKReceipt: money.Baht(50000)},
+	}
+	store.versionTaxConsts = map[int64]map[int][]engine.TaxConst{
+		4: {2567: {
+			engine.NewTaxConst(money.Zero, money.Baht(150000), 0),
+			engine.NewTaxConst(money.Baht(150000), money.Unlimited, 20),
+		}},
+	}
+
+	tt := []struct {
+		name        string
+		query       string
+		wantCode    int
+		wantTax     money.Money
+		wantVersion int64
+		wantBody    Err
+		err         error
+	}{
+		{
+			name:        "current settings when settingsVersion is not given",
+			wantCode:    http.StatusOK,
+			wantTax:     money.Baht(29000),
+			wantVersion: 2,
+		},
+		{
+			name:        "historical settings when settingsVersion is given",
+			query:       "?settingsVersion=1",
+			wantCode:    http.StatusOK,
+			wantTax:     money.Baht(25000),
+			wantVersion: 1,
+		},
+		{
+			name:        "historical tax brackets when settingsVersion is given",
+			query:       "?settingsVersion=4",
+			wantCode:    http.StatusOK,
+			wantTax:     money.Baht(58000),
+			wantVersion: 4,
+		},
+		{
+			name:     "error when settingsVersion is not stored",
+			query:    "?settingsVersion=3",
+			wantCode: http.StatusBadRequest,
+			wantBody: Err{Message: "SettingsVersion '3' is not found."},
+		},
+		{
+			name:     "error 500 when the current settings are not stored",
+			err:      repo.ErrNotFound,
+			wantCode: http.StatusInternalServerError,
+			wantBody: Err{Message: repo.ErrNotFound.Error()},
+		},
+		{
+			name:     "error when settingsVersion is not a positive number",
+			query:    "?settingsVersion=0",
+			wantCode: http.StatusBadRequest,
+			wantBody: Err{Message: "Invalid settingsVersion is required a positive number"},
+		},
+	}
+
+	for _, tCase := range tt {
+		t.Run(tCase.name, func(t *testing.T) {
+			ie := req.IncomeExpense{TotalIncome: money.Baht(500000)}
+			bytesObj, _ := json.Marshal(ie)
+
+			req := httptest.NewRequest(http.MethodPost, "/tax/calculations"+tCase.query, strings.NewReader(string(bytesObj)))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+
+			e := echo.New()
+			c := e.NewContext(req, rec)
+
+			s := store
+			s.err = tCase.err
+			h := New(s)
+
+			h.Calculation(c)
+			if rec.Code != tCase.wantCode {
+				t.Fatalf("expected status %v but got %v", tCase.wantCode, rec.Code)
+			}
+			if tCase.wantCode != http.StatusOK {
+				var got Err
+				json.Unmarshal(rec.Body.Bytes(), &got)
+				if got != tCase.wantBody {
+					t.Errorf("expected %v but got %v", tCase.wantBody, got)
+				}
+				return
+			}
+			var got resp.Tax
+			if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+				t.Errorf("unable to unmarshal json: %v", err)
+			}
+			if got.Tax != tCase.wantTax || got.SettingsVersion != tCase.wantVersion {
+				t.Errorf("expected %v %v but got %v %v", tCase.wantTax, tCase.wantVersion, got.Tax, got.SettingsVersion)
+			}
+		})
+	}
+}
+
+func TestTaxCalculationExplain(t *testing.T) {
+	tt := []struct {
+		name      string
+		query     string
+		wantCode  int
+		wantSteps []string
+	}{
+		{
+			name:      "steps from income to tax when explain is true",
+			query:     "?explain=true",
+			wantCode:  http.StatusOK,
+			wantSteps: []string{"income", "personal", "allowance", "net-income", "level", "level", "wht", "tax"},
+		},
+		{
+			name:     "no steps when explain is not given",
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "error when explain is not a boolean",
+			query:    "?explain=yes please",
+			wantCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tCase := range tt {
+		t.Run(tCase.name, func(t *testing.T) {
+			ie := req.IncomeExpense{
+				TotalIncome: money.Baht(500000),
+				Wht:         money.Baht(10000),
+				Allowances:  []req.Allowance{{AllowanceType: "k-receipt", Amount: money.Baht(20000)}},
+			}
+			bytesObj, _ := json.Marshal(ie)
+
+			req := httptest.NewRequest(http.MethodPost, "/tax/calculations"+strings.ReplaceAll(tCase.query, " ", "%20"), strings.NewReader(string(bytesObj)))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+
+			e := echo.New()
+			c := e.NewContext(req, rec)
+			c.SetPath("/tax/calculations")
+
+			h := New(stubStore)
+
+			h.Calculation(c)
+			if rec.Code != tCase.wantCode {
+				t.Fatalf("expected status %v but got %v", tCase.wantCode, rec.Code)
+			}
+			if tCase.wantCode != http.StatusOK {
+				return
+			}
+
+			var got resp.Tax
+			if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+				t.Errorf("unable to unmarshal json: %v", err)
+			}
+			var gotSteps []string
+			for _, s := range got.Steps {
+				gotSteps = append(gotSteps, s.Step)
+			}
+			if !reflect.DeepEqual(gotSteps, tCase.wantSteps) {
+				t.Errorf("expected %v but got %v", tCase.wantSteps, gotSteps)
+			}
+		})
+	}
+}
+
+func TestTaxCalculationWithStoredBrackets(t *testing.T) {
+	store := stubStore
+	store.taxConsts = map[int][]engine.TaxConst{
+		2569: {
+			engine.NewTaxConst(money.Baht(0), money.Baht(100000), 0),
+			engine.NewTaxConst(money.Baht(100000), money.Unlimited, 10),
+		},
+	}
+
+	ie := req.IncomeExpense{TaxYear: 2569, TotalIncome: money.Baht(500000)}
+	want := resp.Tax{TaxYear: 2569, Tax: money.Baht(34000), Rates: resp.Rates{NetIncome: money.Baht(440000), MarginalRate: 10, EffectiveRate: 6.8, EffectiveRateOnNet: 7.73}, TaxLevels: []resp.TaxLevel{
+		{Tax: money.Baht(0), Level: "0-100,000", Rate: 0, Taxable: money.Baht(100000)},
+		{Tax: money.Baht(34000), Level: "100,001 ขึ้นไป", Rate: 10, Taxable: money.Baht(340000)},
+	}}
+
+	bytesObj, _ := json.Marshal(ie)
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(string(bytesObj)))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+
+	e := echo.New()
+	c := e.NewContext(req, rec)
+	c.SetPath("/tax/calculations")
+
+	h := New(store)
+
+	h.Calculation(c)
+	var got resp.Tax
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Errorf("unable to unmarshal json: %v", err)
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v but got %v", want, got)
+	}
+}
+
+func TestTaxCalculationCsv(t *testing.T) {
+
+	tt := []struct {
+		name    string
+		csvPath string
+		csvName string
+		want    resp.Taxes
+	}{
+		{
+			name:    "calculate tax is correct when attach correct CSV file",
+			csvPath: "./csv_src/tax_csv.csv",
+			csvName: "tax.csv",
+			want: resp.Taxes{
+				Taxes: []resp.TaxWithIncome{
+					{TaxYear: 2567, TotalIncome: money.Baht(500000), Tax: money.Baht(29000), TaxRefund: money.Baht(0)},
+					{TaxYear: 2567, TotalIncome: money.Baht(600000), Tax: money.Baht(0), TaxRefund: money.Baht(2000)},
+					{TaxYear: 2567, TotalIncome: money.Baht(750000), Tax: money.Baht(11250), TaxRefund: money.Baht(0)},
+				},
+			},
+		},
+		{
+			name:    "calculate tax by tax year when attach CSV file with taxYear column",
+			csvPath: "./csv_src/tax_csv_year.csv",
+			csvName: "tax.csv",
+			want: resp.Taxes{
+				Taxes: []resp.TaxWithIncome{
+					{TaxYear: 2566, TotalIncome: money.Baht(500000), Tax: money.Baht(29000), TaxRefund: money.Baht(0)},
+					{TaxYear: 2568, TotalIncome: money.Baht(600000), Tax: money.Baht(0), TaxRefund: money.Baht(2000)},
+				},
+			},
+		},
+		{
+			name:    "assess late filing when attach CSV file with filingDate column",
+			csvPath: "./csv_src/tax_csv_late.csv",
+			csvName: "tax.csv",
+			want: resp.Taxes{
+				Taxes: []resp.TaxWithIncome{
+					{TaxYear: 2567, TotalIncome: money.Baht(500000), Tax: money.Baht(29000), TaxRefund: money.Baht(0),
+						Late: resp.Late{DueDate: "2025-03-31", Surcharge: moneyPtr(435), Penalty: moneyPtr(1000), TotalPayable: moneyPtr(30435)}},
+					{TaxYear: 2567, TotalIncome: money.Baht(600000), Tax: money.Baht(0), TaxRefund: money.Baht(2000)},
+					{TaxYear: 2567, TotalIncome: money.Baht(600000), Tax: money.Baht(0), TaxRefund: money.Baht(2000),
+						Late: resp.Late{DueDate: "2025-03-31", Surcharge: moneyPtr(0), Penalty: moneyPtr(200), TotalPayable: moneyPtr(200)}},
+				},
+			},
+		},
+		{
+			name:    "calculate tax with both methods when attach CSV file with income category columns",
+			csvPath: "./csv_src/tax_csv_category.csv",
+			csvName: "tax.csv",
+			want: resp.Taxes{
+				Taxes: []resp.TaxWithIncome{
+					{TaxYear: 2567, TotalIncome: money.Baht(500000), Tax: money.Baht(29000), TaxRefund: money.Baht(0)},
+					{TaxYear: 2567, TotalIncome: money.Baht(3000000), Tax: money.Baht(138000), TaxRefund: money.Baht(0),
+						TaxMethod: "progressive", TaxMethods: []resp.TaxMethod{
+							{Method: "progressive", Tax: money.Baht(138000)},
+							{Method: "gross-income", Tax: money.Baht(15000)},
+						}},
+				},
+			},
+		},
+	}
+
+	for _, tCase := range tt {
+
+		t.Run(tCase.name, func(t *testing.T) {
+
+			body := new(bytes.Buffer)
+			writer := multipart.NewWriter(body)
+			part, _ := writer.CreateFormFile("taxFile", tCase.csvName)
+			file, err := os.Open(tCase.csvPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer file.Close()
+			if _, err := io.Copy(part, file); err != nil {
+				t.Fatal(err)
+			}
+			writer.Close()
+
+			req := httptest.NewRequest(http.MethodPost, "/tax/calculations/upload-csv", body)
+			req.Header.Set(echo.HeaderContentType, writer.FormDataContentType())
+			rec := httptest.NewRecorder()
+
+			e := echo.New()
+			c := e.NewContext(req, rec)
+			c.SetPath("/tax/calculations/upload-csv")
+
+			h := New(stubStore)
+
+			var want = tCase.want
+
+			h.CalculationCSV(c)
+			var got resp.Taxes
+			gotJson := rec.Body.Bytes()
+			if err := json.Unmarshal(gotJson, &got); err != nil {
+				t.Errorf("unable to unmarshal json: %v", err)
+			}
+
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("expected %v but got %v", want, got)
+			}
+
+		})
+	}
+}
+
+func TestTaxCalculationCsvSave(t *testing.T) {
+	tt := []struct {
+		name      string
+		file      string
+		wantInput req.IncomeExpense
+	}{
+		{
+			name:      "rows should be saved with their input",
+			file:      "./csv_src/tax_csv.csv",
+			wantInput: req.IncomeExpense{TotalIncome: money.Baht(500000), Allowances: []req.Allowance{{AllowanceType: "donation", Amount: money.Baht(0)}}},
+		},
+		{
+			name:      "rows should be saved with their filing date",
+			file:      "./csv_src/tax_csv_late.csv",
+			wantInput: req.IncomeExpense{TotalIncome: money.Baht(500000), Allowances: []req.Allowance{{AllowanceType: "donation", Amount: money.Baht(0)}}, FilingDate: "2025-04-30"},
+		},
+	}
+
+	for _, tCase := range tt {
+		t.Run(tCase.name, func(t *testing.T) {
+			store := stubStore
+			store.calculations = map[string]repo.Calculation{}
+
+			body := new(bytes.Buffer)
+			writer := multipart.NewWriter(body)
+			part, _ := writer.CreateFormFile("taxFile", "tax.csv")
+			file, err := os.Open(tCase.file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer file.Close()
+			if _, err := io.Copy(part, file); err != nil {
+				t.Fatal(err)
+			}
+			writer.Close()
+
+			csvReq := httptest.NewRequest(http.MethodPost, "/tax/calculations/upload-csv?save=true", body)
+			csvReq.Header.Set(echo.HeaderContentType, writer.FormDataContentType())
+			rec := httptest.NewRecorder()
+
+			e := echo.New()
+			c := e.NewContext(csvReq, rec)
+
+			h := New(store)
+
+			h.CalculationCSV(c)
+			var got resp.Taxes
+			if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+				t.Errorf("unable to unmarshal json: %v", err)
+			}
+			for i, tax := range got.Taxes {
+				if tax.CalculationID != calculationID(i+1) {
+					t.Errorf("expected calculation id %v but got %v", calculationID(i+1), tax.CalculationID)
+				}
+			}
+			if len(store.calculations) != 3 {
+				t.Fatalf("expected %v calculations saved but got %v", 3, len(store.calculations))
+			}
+
+			var gotInput req.IncomeExpense
+			json.Unmarshal(store.calculations[calculationID(1)].Input, &gotInput)
+			if !reflect.DeepEqual(gotInput, tCase.wantInput) {
+				t.Errorf("expected %v but got %v", tCase.wantInput, gotInput)
+			}
+		})
+	}
+}
+
+func TestTaxCalculationCsvSaveInvalidRow(t *testing.T) {
+	store := stubStore
+	store.calculations = map[string]repo.Calculation{}
+
+	body := new(bytes.Buffer)
+	writer := multipart.NewWriter(body)
+	part, _ := writer.CreateFormFile("taxFile", "tax.csv")
+	file, err := os.Open("./csv_src/tax_csv_invalid.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	if _, err := io.Copy(part, file); err != nil {
+		t.Fatal(err)
+	}
+	writer.Close()
+
+	csvReq := httptest.NewRequest(http.MethodPost, "/tax/calculations/upload-csv?save=true", body)
+	csvReq.Header.Set(echo.HeaderContentType, writer.FormDataContentType())
+	rec := httptest.NewRecorder()
+
+	e := echo.New()
+	c := e.NewContext(csvReq, rec)
+
+	h := New(store)
+
+	h.CalculationCSV(c)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected status %v but got %v", http.StatusBadRequest, rec.Code)
+	}
+	if len(store.calculations) != 0 {
+		t.Errorf("expected no calculation saved but got %v", len(store.calculations))
 	}
 }
@@ -20,7 +20,9 @@ func errorTagFunc[T interface{}](obj interface{}, snp string, fieldname, tagCust
 	rsf := reflect.TypeOf(o)
 
 	for i := 1; i < len(fieldArr); i++ {
-		field, found := rsf.FieldByName(fieldArr[i])
+		// drop the index of a slice element, e.g. Parents[0]
+		name, _, _ := strings.Cut(fieldArr[i], "[")
+		field, found := rsf.FieldByName(name)
 		if found {
 			if fieldArr[i] == fieldname {
 				customMessage := field.Tag.Get(tagCustom)
@@ -29,11 +31,13 @@ func errorTagFunc[T interface{}](obj interface{}, snp string, fieldname, tagCust
 				}
 				return nil
 			} else {
-				if field.Type.Kind() == reflect.Ptr {
+				rsf = field.Type
+				if rsf.Kind() == reflect.Slice {
+					rsf = rsf.Elem()
+				}
+				if rsf.Kind() == reflect.Ptr {
 					// If the field type is a pointer, dereference it
-					rsf = field.Type.Elem()
-				} else {
-					rsf = field.Type
+					rsf = rsf.Elem()
 				}
 			}
 		}